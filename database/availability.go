@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"test/models"
+	"time"
+)
+
+// GetAvailability menghitung availability per target dan global untuk setiap
+// jendela di models.AvailabilityWindows. Bagian yang sudah dirangkum diambil
+// dari rollup per jam (tetap ada setelah history mentah dibersihkan oleh
+// retention), sisanya dihitung dari probe_history. Awal jendela dibulatkan ke
+// awal jam, dan tidak lebih awal dari availabilitySince.
+func (s *Store) GetAvailability(now time.Time) (map[int]models.AvailabilitySet, models.Availability, error) {
+	level := rollupLevels[models.ResolutionHourly]
+
+	// Rollup per jam selalu berurutan, jadi bucket terakhir menandai batas
+	// antara data rollup dan data mentah
+	var coveredUntil time.Time
+	var last time.Time
+	err := s.Db.QueryRow("SELECT bucket FROM " + level.table + " ORDER BY bucket DESC LIMIT 1").Scan(&last)
+	switch {
+	case err == nil:
+		coveredUntil = level.next(last.In(now.Location()))
+	case err != sql.ErrNoRows:
+		return nil, models.Availability{}, err
+	}
+
+	from, err := s.availabilitySince(level, now.Location())
+	if err != nil {
+		return nil, models.Availability{}, err
+	}
+
+	perTarget := make(map[int]models.AvailabilitySet)
+	var global models.Availability
+	for i, window := range models.AvailabilityWindows {
+		d, _ := models.WindowDuration(window)
+		since := level.truncate(now.Add(-d))
+		if since.Before(from) {
+			since = from
+		}
+
+		counts := make(map[int]models.Availability)
+		if coveredUntil.After(since) {
+			if err := s.addAvailabilityCounts(counts, `SELECT url_id, SUM(count), SUM(failures)
+				FROM `+level.table+` WHERE bucket >= ? GROUP BY url_id`, since); err != nil {
+				return nil, models.Availability{}, err
+			}
+		}
+		rawSince := since
+		if coveredUntil.After(rawSince) {
+			rawSince = coveredUntil
+		}
+		if err := s.addAvailabilityCounts(counts, `SELECT url_id, COUNT(*), SUM(CASE WHEN is_up = 0 THEN 1 ELSE 0 END)
+			FROM probe_history WHERE timestamp >= ? GROUP BY url_id`, rawSince); err != nil {
+			return nil, models.Availability{}, err
+		}
+
+		total := models.Availability{Window: window}
+		for urlID, a := range counts {
+			if _, ok := perTarget[urlID]; !ok {
+				perTarget[urlID] = make(models.AvailabilitySet, len(models.AvailabilityWindows))
+				for j, w := range models.AvailabilityWindows {
+					perTarget[urlID][j].Window = w
+				}
+			}
+			perTarget[urlID][i].Total = a.Total
+			perTarget[urlID][i].Failures = a.Failures
+			total.Total += a.Total
+			total.Failures += a.Failures
+		}
+		if window == models.SLAWindow {
+			global = total
+		}
+	}
+	return perTarget, global, nil
+}
+
+// availabilitySince mengembalikan awal jam pertama setelah database lama
+// di-upgrade (setting availability_since), atau waktu kosong untuk database
+// yang sejak awal mencatat hasil probe. History sebelum itu tidak punya
+// kolom is_up yang benar.
+func (s *Store) availabilitySince(level rollupLevel, loc *time.Location) (time.Time, error) {
+	value, err := s.GetSetting("availability_since", "")
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, nil
+	}
+	return level.next(level.truncate(t.In(loc))), nil
+}
+
+// addAvailabilityCounts menjalankan query yang mengembalikan (url_id, total,
+// failures) lalu menambahkannya ke counts
+func (s *Store) addAvailabilityCounts(counts map[int]models.Availability, query string, since time.Time) error {
+	rows, err := s.Db.Query(query, since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var urlID int
+		var total, failures int64
+		if err := rows.Scan(&urlID, &total, &failures); err != nil {
+			return err
+		}
+		a := counts[urlID]
+		a.Total += total
+		a.Failures += failures
+		counts[urlID] = a
+	}
+	return rows.Err()
+}
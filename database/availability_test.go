@@ -0,0 +1,69 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAvailabilityIgnoresLegacyHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "probe.db")
+
+	// Skema history sebelum hasil probe dicatat: hanya latency
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = legacy.Exec(`CREATE TABLE probe_history (
+		"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		"url_id" INTEGER,
+		"latency_ms" INTEGER,
+		"timestamp" DATETIME
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-3 * time.Hour)
+	for i := 0; i < 5; i++ {
+		if _, err := legacy.Exec("INSERT INTO probe_history (url_id, latency_ms, timestamp) VALUES (1, 30000, ?)", old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	legacy.Close()
+
+	s := NewStore(path)
+	defer s.Db.Close()
+
+	later := time.Now().Add(2 * time.Hour)
+	addHistoryAt(t, s, 1, later, true)
+	addHistoryAt(t, s, 1, later, false)
+
+	perTarget, _, err := s.GetAvailability(later.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range perTarget[1] {
+		if a.Total != 2 || a.Failures != 1 {
+			t.Errorf("%s: %d probes, %d failures, want 2 and 1", a.Window, a.Total, a.Failures)
+		}
+	}
+}
+
+func TestAvailabilityNewDatabaseHasNoCutoff(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	addHistoryAt(t, s, 1, now.Add(-10*24*time.Hour), false)
+	addHistoryAt(t, s, 1, now.Add(-time.Minute), true)
+
+	perTarget, _, err := s.GetAvailability(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"24h": 1, "7d": 1, "30d": 2, "90d": 2}
+	for _, a := range perTarget[1] {
+		if a.Total != want[a.Window] {
+			t.Errorf("%s: %d probes, want %d", a.Window, a.Total, want[a.Window])
+		}
+	}
+}
@@ -2,7 +2,11 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"test/models"
 	"time"
 
@@ -29,12 +33,92 @@ func NewStore(dbPath string) *Store {
 		"last_checked" DATETIME,
 		"first_up_time" DATETIME DEFAULT NULL,
 		"total_probe_count" INTEGER DEFAULT 0,
-		"total_latency_sum" INTEGER DEFAULT 0
+		"total_latency_sum" INTEGER DEFAULT 0,
+		"method" TEXT NOT NULL DEFAULT 'GET',
+		"headers" TEXT NOT NULL DEFAULT '{}',
+		"body" TEXT NOT NULL DEFAULT '',
+		"expected_status" TEXT NOT NULL DEFAULT '200',
+		"assertions" TEXT NOT NULL DEFAULT '[]',
+		"last_error" TEXT NOT NULL DEFAULT '',
+		"last_assertions" TEXT NOT NULL DEFAULT '[]',
+		"cert_expiry" DATETIME DEFAULT NULL,
+		"cert_issuer" TEXT NOT NULL DEFAULT '',
+		"cert_sans" TEXT NOT NULL DEFAULT '',
+		"cert_warn_days" INTEGER NOT NULL DEFAULT 14,
+		"probe_type" TEXT NOT NULL DEFAULT 'http',
+		"tcp_send" TEXT NOT NULL DEFAULT '',
+		"tcp_expect" TEXT NOT NULL DEFAULT '',
+		"is_up" INTEGER NOT NULL DEFAULT 0,
+		"dns_record_type" TEXT NOT NULL DEFAULT 'A',
+		"dns_resolver" TEXT NOT NULL DEFAULT '',
+		"dns_expected" TEXT NOT NULL DEFAULT '',
+		"check_interval" TEXT NOT NULL DEFAULT '',
+		"retry_count" INTEGER NOT NULL DEFAULT 0,
+		"retry_delay_ms" INTEGER NOT NULL DEFAULT 1000,
+		"failure_threshold" INTEGER NOT NULL DEFAULT 1,
+		"last_probe_up" INTEGER NOT NULL DEFAULT 0,
+		"last_attempts" INTEGER NOT NULL DEFAULT 0,
+		"consecutive_failures" INTEGER NOT NULL DEFAULT 0,
+		"last_error_class" TEXT NOT NULL DEFAULT '',
+		"retention_days" INTEGER NOT NULL DEFAULT 0,
+		"sla_target" REAL NOT NULL DEFAULT 0,
+		"latency_warn_ms" INTEGER NOT NULL DEFAULT 0,
+		"latency_crit_ms" INTEGER NOT NULL DEFAULT 0,
+		"latency_avg_probes" INTEGER NOT NULL DEFAULT 1,
+		"latency_level" TEXT NOT NULL DEFAULT '',
+		"latency_check_ms" INTEGER NOT NULL DEFAULT 0
 	);`
 	_, err = db.Exec(createTableSQL)
 	if err != nil {
 		log.Fatalf("Gagal membuat tabel urls: %v", err)
 	}
+	// Kolom tambahan untuk database lama (dibuat sebelum kolom ini ada)
+	addColumnIfMissing(db, "urls", "method", `TEXT NOT NULL DEFAULT 'GET'`)
+	addColumnIfMissing(db, "urls", "headers", `TEXT NOT NULL DEFAULT '{}'`)
+	addColumnIfMissing(db, "urls", "body", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "expected_status", `TEXT NOT NULL DEFAULT '200'`)
+	addColumnIfMissing(db, "urls", "assertions", `TEXT NOT NULL DEFAULT '[]'`)
+	addColumnIfMissing(db, "urls", "last_error", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "last_assertions", `TEXT NOT NULL DEFAULT '[]'`)
+	addColumnIfMissing(db, "urls", "cert_expiry", `DATETIME DEFAULT NULL`)
+	addColumnIfMissing(db, "urls", "cert_issuer", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "cert_sans", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "cert_warn_days", `INTEGER NOT NULL DEFAULT 14`)
+	addColumnIfMissing(db, "urls", "probe_type", `TEXT NOT NULL DEFAULT 'http'`)
+	addColumnIfMissing(db, "urls", "tcp_send", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "tcp_expect", `TEXT NOT NULL DEFAULT ''`)
+	if addColumnIfMissing(db, "urls", "is_up", `INTEGER NOT NULL DEFAULT 0`) {
+		backfillIsUp(db)
+	}
+	addColumnIfMissing(db, "urls", "dns_record_type", `TEXT NOT NULL DEFAULT 'A'`)
+	addColumnIfMissing(db, "urls", "dns_resolver", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "dns_expected", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "check_interval", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "retry_count", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "urls", "retry_delay_ms", `INTEGER NOT NULL DEFAULT 1000`)
+	addColumnIfMissing(db, "urls", "failure_threshold", `INTEGER NOT NULL DEFAULT 1`)
+	if addColumnIfMissing(db, "urls", "last_probe_up", `INTEGER NOT NULL DEFAULT 0`) {
+		// Sebelumnya hasil mentah dan status terkonfirmasi selalu sama
+		if _, err := db.Exec("UPDATE urls SET last_probe_up = is_up"); err != nil {
+			log.Fatalf("Gagal backfill last_probe_up: %v", err)
+		}
+	}
+	if addColumnIfMissing(db, "urls", "last_attempts", `INTEGER NOT NULL DEFAULT 0`) {
+		// last_attempts 0 menandai target yang belum pernah diprobe
+		if _, err := db.Exec(`UPDATE urls SET last_attempts = 1
+			WHERE is_up = 1 OR total_probe_count > 0 OR last_status != 0 OR last_error != ''`); err != nil {
+			log.Fatalf("Gagal backfill last_attempts: %v", err)
+		}
+	}
+	addColumnIfMissing(db, "urls", "consecutive_failures", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "urls", "last_error_class", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "retention_days", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "urls", "sla_target", `REAL NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "urls", "latency_warn_ms", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "urls", "latency_crit_ms", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "urls", "latency_avg_probes", `INTEGER NOT NULL DEFAULT 1`)
+	addColumnIfMissing(db, "urls", "latency_level", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "urls", "latency_check_ms", `INTEGER NOT NULL DEFAULT 0`)
 
 	// --- TABEL SETTINGS ---
 	createSettingsTableSQL := `
@@ -51,6 +135,23 @@ func NewStore(dbPath string) *Store {
 	if err != nil {
 		log.Fatalf("Gagal set default interval: %v", err)
 	}
+	// Default jumlah probe paralel (total dan per host)
+	_, err = db.Exec(`INSERT OR IGNORE INTO settings (key, value) VALUES
+		('probe_concurrency', '10'),
+		('probe_per_host', '2')`)
+	if err != nil {
+		log.Fatalf("Gagal set default concurrency: %v", err)
+	}
+	// Default lama penyimpanan history (hari)
+	_, err = db.Exec("INSERT OR IGNORE INTO settings (key, value) VALUES ('history_retention_days', '30')")
+	if err != nil {
+		log.Fatalf("Gagal set default retensi: %v", err)
+	}
+	// Default target availability (persen)
+	_, err = db.Exec("INSERT OR IGNORE INTO settings (key, value) VALUES ('sla_target', '99.9')")
+	if err != nil {
+		log.Fatalf("Gagal set default SLA: %v", err)
+	}
 
 	// --- TABEL PROBE HISTORY ---
 	createHistoryTableSQL := `
@@ -59,16 +160,203 @@ func NewStore(dbPath string) *Store {
 		"url_id" INTEGER,
 		"latency_ms" INTEGER,
 		"timestamp" DATETIME,
+		"dns_ms" INTEGER NOT NULL DEFAULT 0,
+		"connect_ms" INTEGER NOT NULL DEFAULT 0,
+		"tls_ms" INTEGER NOT NULL DEFAULT 0,
+		"ttfb_ms" INTEGER NOT NULL DEFAULT 0,
+		"transfer_ms" INTEGER NOT NULL DEFAULT 0,
+		"attempts" INTEGER NOT NULL DEFAULT 1,
+		"status_code" INTEGER NOT NULL DEFAULT 0,
+		"is_up" INTEGER NOT NULL DEFAULT 1,
+		"error_class" TEXT NOT NULL DEFAULT '',
+		"error_message" TEXT NOT NULL DEFAULT '',
 		FOREIGN KEY(url_id) REFERENCES urls(id) ON DELETE CASCADE
 	);`
 	_, err = db.Exec(createHistoryTableSQL)
 	if err != nil {
 		log.Fatalf("Gagal membuat tabel probe_history: %v", err)
 	}
+	addColumnIfMissing(db, "probe_history", "dns_ms", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "probe_history", "connect_ms", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "probe_history", "tls_ms", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "probe_history", "ttfb_ms", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "probe_history", "transfer_ms", `INTEGER NOT NULL DEFAULT 0`)
+	addColumnIfMissing(db, "probe_history", "attempts", `INTEGER NOT NULL DEFAULT 1`)
+	addColumnIfMissing(db, "probe_history", "status_code", `INTEGER NOT NULL DEFAULT 0`)
+	if addColumnIfMissing(db, "probe_history", "is_up", `INTEGER NOT NULL DEFAULT 1`) {
+		// History lama tidak mencatat hasil probe (response 5xx pun tersimpan),
+		// jadi availability hanya dihitung dari probe setelah upgrade
+		if _, err := db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES ('availability_since', ?)",
+			time.Now().Format(time.RFC3339)); err != nil {
+			log.Fatalf("Gagal menyimpan availability_since: %v", err)
+		}
+	}
+	addColumnIfMissing(db, "probe_history", "error_class", `TEXT NOT NULL DEFAULT ''`)
+	addColumnIfMissing(db, "probe_history", "error_message", `TEXT NOT NULL DEFAULT ''`)
+	// Index untuk query per rentang waktu dan cleanup retensi
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_probe_history_url_time ON probe_history (url_id, timestamp);
+		CREATE INDEX IF NOT EXISTS idx_probe_history_time ON probe_history (timestamp)`)
+	if err != nil {
+		log.Fatalf("Gagal membuat index probe_history: %v", err)
+	}
+
+	// --- TABEL ROLLUP (ringkasan history per jam dan per hari) ---
+	for _, table := range []string{"probe_rollup_hourly", "probe_rollup_daily"} {
+		_, err = db.Exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			"url_id" INTEGER NOT NULL,
+			"bucket" DATETIME NOT NULL,
+			"count" INTEGER NOT NULL DEFAULT 0,
+			"failures" INTEGER NOT NULL DEFAULT 0,
+			"min_ms" INTEGER NOT NULL DEFAULT 0,
+			"avg_ms" INTEGER NOT NULL DEFAULT 0,
+			"max_ms" INTEGER NOT NULL DEFAULT 0,
+			"p50_ms" INTEGER NOT NULL DEFAULT 0,
+			"p95_ms" INTEGER NOT NULL DEFAULT 0,
+			"p99_ms" INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (url_id, bucket)
+		);`, table))
+		if err != nil {
+			log.Fatalf("Gagal membuat tabel %s: %v", table, err)
+		}
+		addColumnIfMissing(db, table, "p90_ms", `INTEGER NOT NULL DEFAULT 0`)
+	}
+
+	// --- TABEL SCHEDULER RUNS ---
+	createRunsTableSQL := `
+	CREATE TABLE IF NOT EXISTS scheduler_runs (
+		"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		"scope" TEXT NOT NULL DEFAULT 'global',
+		"url_id" INTEGER DEFAULT NULL,
+		"started_at" DATETIME NOT NULL,
+		"finished_at" DATETIME NOT NULL,
+		"targets" INTEGER NOT NULL DEFAULT 0,
+		"failures" INTEGER NOT NULL DEFAULT 0,
+		"skipped" INTEGER NOT NULL DEFAULT 0
+	);`
+	_, err = db.Exec(createRunsTableSQL)
+	if err != nil {
+		log.Fatalf("Gagal membuat tabel scheduler_runs: %v", err)
+	}
+	addColumnIfMissing(db, "scheduler_runs", "error", `TEXT NOT NULL DEFAULT ''`)
+
+	// --- TABEL INCIDENTS (periode down per target) ---
+	createIncidentsTableSQL := `
+	CREATE TABLE IF NOT EXISTS incidents (
+		"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		"url_id" INTEGER NOT NULL,
+		"started_at" DATETIME NOT NULL,
+		"ended_at" DATETIME DEFAULT NULL,
+		"status_code" INTEGER NOT NULL DEFAULT 0,
+		"error_class" TEXT NOT NULL DEFAULT '',
+		"error_message" TEXT NOT NULL DEFAULT '',
+		"probe_count" INTEGER NOT NULL DEFAULT 0,
+		FOREIGN KEY(url_id) REFERENCES urls(id) ON DELETE CASCADE
+	);`
+	_, err = db.Exec(createIncidentsTableSQL)
+	if err != nil {
+		log.Fatalf("Gagal membuat tabel incidents: %v", err)
+	}
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_incidents_url_started ON incidents (url_id, started_at)")
+	if err != nil {
+		log.Fatalf("Gagal membuat index incidents: %v", err)
+	}
+
+	// --- TABEL NOTIFIKASI (channel dan log pengiriman) ---
+	createChannelsTableSQL := `
+	CREATE TABLE IF NOT EXISTS notification_channels (
+		"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		"name" TEXT NOT NULL DEFAULT '',
+		"type" TEXT NOT NULL DEFAULT 'webhook',
+		"url" TEXT NOT NULL DEFAULT '',
+		"secret" TEXT NOT NULL DEFAULT '',
+		"enabled" INTEGER NOT NULL DEFAULT 1,
+		"created_at" DATETIME NOT NULL
+	);`
+	_, err = db.Exec(createChannelsTableSQL)
+	if err != nil {
+		log.Fatalf("Gagal membuat tabel notification_channels: %v", err)
+	}
+	createDeliveriesTableSQL := `
+	CREATE TABLE IF NOT EXISTS notification_deliveries (
+		"id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		"channel_id" INTEGER NOT NULL,
+		"url_id" INTEGER NOT NULL DEFAULT 0,
+		"event" TEXT NOT NULL DEFAULT '',
+		"success" INTEGER NOT NULL DEFAULT 0,
+		"status_code" INTEGER NOT NULL DEFAULT 0,
+		"attempts" INTEGER NOT NULL DEFAULT 0,
+		"error" TEXT NOT NULL DEFAULT '',
+		"created_at" DATETIME NOT NULL
+	);`
+	_, err = db.Exec(createDeliveriesTableSQL)
+	if err != nil {
+		log.Fatalf("Gagal membuat tabel notification_deliveries: %v", err)
+	}
 
 	return &Store{Db: db}
 }
 
+// backfillIsUp mengisi kolom is_up untuk database lama, di mana status up
+// sebelumnya dihitung dari last_status dan last_error.
+func backfillIsUp(db *sql.DB) {
+	rows, err := db.Query("SELECT id, last_status, expected_status, last_error FROM urls")
+	if err != nil {
+		log.Fatalf("Gagal membaca urls untuk backfill is_up: %v", err)
+	}
+	var upIDs []int
+	for rows.Next() {
+		var u models.TargetURL
+		if err := rows.Scan(&u.ID, &u.LastStatus, &u.ExpectedStatus, &u.LastError); err != nil {
+			log.Fatalf("Gagal membaca urls untuk backfill is_up: %v", err)
+		}
+		if u.StatusAccepted(u.LastStatus) && u.LastError == "" {
+			upIDs = append(upIDs, u.ID)
+		}
+	}
+	rows.Close()
+
+	for _, id := range upIDs {
+		if _, err := db.Exec("UPDATE urls SET is_up = 1 WHERE id = ?", id); err != nil {
+			log.Fatalf("Gagal backfill is_up: %v", err)
+		}
+	}
+}
+
+// addColumnIfMissing menambahkan kolom ke tabel yang sudah ada jika kolom
+// tersebut belum ada. Mengembalikan true jika kolom baru saja ditambahkan.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) bool {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		log.Fatalf("Gagal membaca struktur tabel %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			log.Fatalf("Gagal membaca struktur tabel %s: %v", table, err)
+		}
+		if name == column {
+			return false
+		}
+	}
+	rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN "%s" %s`, table, column, definition))
+	if err != nil {
+		log.Fatalf("Gagal menambah kolom %s.%s: %v", table, column, err)
+	}
+	return true
+}
+
 // --- FUNGSI SETTINGS ---
 func (s *Store) GetScheduleInterval() (string, error) {
 	var interval string
@@ -81,9 +369,105 @@ func (s *Store) SetScheduleInterval(interval string) error {
 	return err
 }
 
+// GetSetting mengambil nilai setting, atau def jika key belum ada
+func (s *Store) GetSetting(key, def string) (string, error) {
+	var value string
+	err := s.Db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return def, nil
+	}
+	return value, err
+}
+
+// GetIntSetting mengambil setting berupa angka, atau def jika kosong/tidak valid
+func (s *Store) GetIntSetting(key string, def int) int {
+	value, err := s.GetSetting(key, "")
+	if err != nil || value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// GetFloatSetting membaca setting berupa angka desimal, atau def jika belum
+// diatur atau tidak valid
+func (s *Store) GetFloatSetting(key string, def float64) float64 {
+	value, err := s.GetSetting(key, "")
+	if err != nil || value == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// SetSetting menyimpan (insert atau update) satu setting
+func (s *Store) SetSetting(key, value string) error {
+	_, err := s.Db.Exec("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value", key, value)
+	return err
+}
+
 // --- FUNGSI URLS ---
+
+// urlColumns adalah daftar kolom yang dibaca oleh scanURL (urutannya harus sama)
+const urlColumns = `id, url, last_status, last_latency_ms, last_checked, first_up_time, total_probe_count, total_latency_sum,
+	method, headers, body, expected_status, assertions, last_error, last_assertions,
+	cert_expiry, cert_issuer, cert_sans, cert_warn_days, probe_type, tcp_send, tcp_expect, is_up,
+	dns_record_type, dns_resolver, dns_expected, check_interval,
+	retry_count, retry_delay_ms, failure_threshold, last_probe_up, last_attempts, consecutive_failures, last_error_class,
+	retention_days, sla_target, latency_warn_ms, latency_crit_ms, latency_avg_probes, latency_level, latency_check_ms`
+
+// rowScanner dipenuhi oleh *sql.Row dan *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanURL membaca satu baris urls (sesuai urlColumns) ke TargetURL
+func scanURL(row rowScanner) (models.TargetURL, error) {
+	var u models.TargetURL
+	var lastChecked sql.NullTime
+	var headers, assertions, lastAssertions, certSANs string
+	err := row.Scan(&u.ID, &u.URL, &u.LastStatus, &u.LastLatencyMs, &lastChecked, &u.FirstUpTime, &u.TotalProbeCount, &u.TotalLatencySum,
+		&u.Method, &headers, &u.Body, &u.ExpectedStatus, &assertions, &u.LastError, &lastAssertions,
+		&u.CertExpiry, &u.CertIssuer, &certSANs, &u.CertWarnDays, &u.ProbeType, &u.TCPSend, &u.TCPExpect, &u.IsUp,
+		&u.DNSRecordType, &u.DNSResolver, &u.DNSExpected, &u.CheckInterval,
+		&u.RetryCount, &u.RetryDelayMs, &u.FailureThreshold, &u.LastProbeUp, &u.LastAttempts, &u.ConsecutiveFailures, &u.LastErrorClass,
+		&u.RetentionDays, &u.SLATarget, &u.LatencyWarnMs, &u.LatencyCritMs, &u.LatencyAvgProbes, &u.LatencyLevel, &u.LatencyCheckMs)
+	if err != nil {
+		return u, err
+	}
+	if lastChecked.Valid {
+		u.LastChecked = lastChecked.Time
+	}
+	if headers != "" {
+		if err := json.Unmarshal([]byte(headers), &u.Headers); err != nil {
+			return u, fmt.Errorf("header tidak valid untuk url %d: %w", u.ID, err)
+		}
+	}
+	if assertions != "" {
+		if err := json.Unmarshal([]byte(assertions), &u.Assertions); err != nil {
+			return u, fmt.Errorf("assertion tidak valid untuk url %d: %w", u.ID, err)
+		}
+	}
+	if lastAssertions != "" {
+		if err := json.Unmarshal([]byte(lastAssertions), &u.LastAssertions); err != nil {
+			return u, fmt.Errorf("hasil assertion tidak valid untuk url %d: %w", u.ID, err)
+		}
+	}
+	if certSANs != "" {
+		u.CertSANs = strings.Split(certSANs, ",")
+	}
+	u.IsDegraded = u.IsUp && (u.CertExpiringSoon() || u.LatencyLevel != models.LatencyOK)
+	return u, nil
+}
+
 func (s *Store) GetAllURLs() ([]models.TargetURL, error) {
-	rows, err := s.Db.Query("SELECT id, url, last_status, last_latency_ms, last_checked, first_up_time, total_probe_count, total_latency_sum FROM urls ORDER BY id DESC")
+	rows, err := s.Db.Query("SELECT " + urlColumns + " FROM urls ORDER BY id DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -91,22 +475,78 @@ func (s *Store) GetAllURLs() ([]models.TargetURL, error) {
 
 	var urls []models.TargetURL
 	for rows.Next() {
-		var u models.TargetURL
-		var lastChecked sql.NullTime
-		if err := rows.Scan(&u.ID, &u.URL, &u.LastStatus, &u.LastLatencyMs, &lastChecked, &u.FirstUpTime, &u.TotalProbeCount, &u.TotalLatencySum); err != nil {
+		u, err := scanURL(rows)
+		if err != nil {
 			return nil, err
 		}
-		if lastChecked.Valid {
-			u.LastChecked = lastChecked.Time
-		}
-		u.IsUp = (u.LastStatus == 200)
 		urls = append(urls, u)
 	}
 	return urls, nil
 }
 
-func (s *Store) AddURL(url string) error {
-	_, err := s.Db.Exec("INSERT INTO urls (url, last_checked) VALUES (?, ?)", url, time.Now())
+// GetURL mengambil satu target berdasarkan ID
+func (s *Store) GetURL(id int) (models.TargetURL, error) {
+	return scanURL(s.Db.QueryRow("SELECT "+urlColumns+" FROM urls WHERE id = ?", id))
+}
+
+// AddURL menyimpan target baru beserta konfigurasi request-nya dan
+// mengembalikan ID target tersebut
+func (s *Store) AddURL(u models.TargetURL) (int, error) {
+	if u.Headers == nil {
+		u.Headers = map[string]string{}
+	}
+	headers, err := json.Marshal(u.Headers)
+	if err != nil {
+		return 0, err
+	}
+	if u.Assertions == nil {
+		u.Assertions = []models.Assertion{}
+	}
+	assertions, err := json.Marshal(u.Assertions)
+	if err != nil {
+		return 0, err
+	}
+	if u.Method == "" {
+		u.Method = "GET"
+	}
+	if u.ExpectedStatus == "" {
+		u.ExpectedStatus = models.DefaultExpectedStatus
+	}
+	if u.CertWarnDays <= 0 {
+		u.CertWarnDays = models.DefaultCertWarnDays
+	}
+	if u.ProbeType == "" {
+		u.ProbeType = models.ProbeHTTP
+	}
+	if u.DNSRecordType == "" {
+		u.DNSRecordType = models.DNSRecordA
+	}
+	if u.FailureThreshold <= 0 {
+		u.FailureThreshold = models.DefaultFailureThreshold
+	}
+	if u.LatencyAvgProbes <= 0 {
+		u.LatencyAvgProbes = 1
+	}
+	res, err := s.Db.Exec(`INSERT INTO urls (url, probe_type, method, headers, body, expected_status, assertions, cert_warn_days,
+			tcp_send, tcp_expect, dns_record_type, dns_resolver, dns_expected, check_interval,
+			retry_count, retry_delay_ms, failure_threshold, retention_days, sla_target,
+			latency_warn_ms, latency_crit_ms, latency_avg_probes, last_checked)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		u.URL, u.ProbeType, u.Method, string(headers), u.Body, u.ExpectedStatus, string(assertions), u.CertWarnDays,
+		u.TCPSend, u.TCPExpect, u.DNSRecordType, u.DNSResolver, u.DNSExpected, u.CheckInterval,
+		u.RetryCount, u.RetryDelayMs, u.FailureThreshold, u.RetentionDays, u.SLATarget,
+		u.LatencyWarnMs, u.LatencyCritMs, u.LatencyAvgProbes, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// SetCheckInterval mengubah interval cek milik satu target ("" = ikut
+// interval global)
+func (s *Store) SetCheckInterval(id int, interval string) error {
+	_, err := s.Db.Exec("UPDATE urls SET check_interval = ? WHERE id = ?", interval, id)
 	return err
 }
 
@@ -116,46 +556,158 @@ func (s *Store) DeleteURL(id int) error {
 }
 
 // --- FUNGSI PROBE STATS ---
-func (s *Store) UpdateProbeStats(id int, status int, latency int64, firstUpTime sql.NullTime) error {
-	_, err := s.Db.Exec(`
+// UpdateProbeStats menyimpan hasil probe yang mendapat response. result.Up
+// adalah verdict mentah probe, state adalah status yang sudah dikonfirmasi.
+func (s *Store) UpdateProbeStats(id int, result models.ProbeOutcome, state models.CheckState) error {
+	assertions := result.Assertions
+	if assertions == nil {
+		assertions = []models.AssertionResult{}
+	}
+	lastAssertions, err := json.Marshal(assertions)
+	if err != nil {
+		return err
+	}
+	_, err = s.Db.Exec(`
 		UPDATE urls SET
 			last_status = ?,
 			last_latency_ms = ?,
 			last_checked = ?,
+			last_error = ?,
+			last_error_class = ?,
+			last_assertions = ?,
+			is_up = ?,
 			first_up_time = ?,
+			last_probe_up = ?,
+			last_attempts = ?,
+			consecutive_failures = ?,
+			latency_level = ?,
+			latency_check_ms = ?,
 			total_probe_count = total_probe_count + 1,
 			total_latency_sum = total_latency_sum + ?
 		WHERE id = ?`,
-		status, latency, time.Now(), firstUpTime, latency, id)
+		result.StatusCode, result.LatencyMs, time.Now(), result.FailedAssertion, result.ErrorClass, string(lastAssertions), state.IsUp, state.FirstUpTime,
+		result.Up, result.Attempts, state.ConsecutiveFailures, state.LatencyLevel, state.LatencyCheckMs, result.LatencyMs, id)
 	return err
 }
 
-func (s *Store) UpdateProbeNetworkError(id int, latency int64, firstUpTime sql.NullTime) error {
+// UpdateProbeNetworkError menyimpan hasil run yang gagal mendapat response
+func (s *Store) UpdateProbeNetworkError(id int, result models.ProbeOutcome, state models.CheckState) error {
 	_, err := s.Db.Exec(`
 		UPDATE urls SET
 			last_status = 0,
 			last_latency_ms = ?,
 			last_checked = ?,
-			first_up_time = ?
+			last_error = ?,
+			last_error_class = ?,
+			last_assertions = '[]',
+			is_up = ?,
+			first_up_time = ?,
+			last_probe_up = 0,
+			last_attempts = ?,
+			consecutive_failures = ?,
+			latency_level = ?,
+			latency_check_ms = ?
 		WHERE id = ?`,
-		latency, time.Now(), firstUpTime, id)
+		result.LatencyMs, time.Now(), result.ErrorMessage, result.ErrorClass, state.IsUp, state.FirstUpTime, result.Attempts, state.ConsecutiveFailures,
+		state.LatencyLevel, state.LatencyCheckMs, id)
+	return err
+}
+
+// UpdateCertInfo menyimpan info sertifikat TLS terbaru untuk target
+func (s *Store) UpdateCertInfo(id int, cert models.CertInfo) error {
+	_, err := s.Db.Exec("UPDATE urls SET cert_expiry = ?, cert_issuer = ?, cert_sans = ? WHERE id = ?",
+		cert.Expiry, cert.Issuer, strings.Join(cert.SANs, ","), id)
 	return err
 }
 
 // --- FUNGSI PROBE HISTORY (Diperbarui) ---
 
-// AddProbeHistory menyimpan satu log probe
-func (s *Store) AddProbeHistory(urlID int, latencyMs int64) error {
-	_, err := s.Db.Exec("INSERT INTO probe_history (url_id, latency_ms, timestamp) VALUES (?, ?, ?)",
-		urlID, latencyMs, time.Now())
-	// Juga membersihkan history lama agar DB tidak penuh
-	_, _ = s.Db.Exec("DELETE FROM probe_history WHERE id NOT IN (SELECT id FROM probe_history ORDER BY timestamp DESC LIMIT 1000)")
+// historyColumns adalah kolom yang dibaca oleh scanHistoryRows (alias h = probe_history, u = urls)
+const historyColumns = `h.url_id, u.url, h.latency_ms, h.timestamp,
+	h.dns_ms, h.connect_ms, h.tls_ms, h.ttfb_ms, h.transfer_ms,
+	h.status_code, h.is_up, h.attempts, h.error_class, h.error_message`
+
+// scanHistoryRows membaca semua baris hasil query history (sesuai historyColumns)
+func scanHistoryRows(rows *sql.Rows) ([]models.ProbeHistory, error) {
+	defer rows.Close()
+
+	var history []models.ProbeHistory
+	for rows.Next() {
+		var h models.ProbeHistory
+		if err := rows.Scan(&h.URLID, &h.URL, &h.LatencyMs, &h.Timestamp,
+			&h.DNSMs, &h.ConnectMs, &h.TLSMs, &h.TTFBMs, &h.TransferMs,
+			&h.StatusCode, &h.IsUp, &h.Attempts, &h.ErrorClass, &h.ErrorMessage); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// AddProbeHistory menyimpan satu log probe (berhasil maupun gagal) beserta
+// rincian fase latency, status code, verdict dan error
+func (s *Store) AddProbeHistory(urlID int, result models.ProbeOutcome) error {
+	t := result.Timings
+	_, err := s.Db.Exec(`
+		INSERT INTO probe_history (url_id, latency_ms, timestamp, dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, attempts,
+			status_code, is_up, error_class, error_message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		urlID, result.LatencyMs, time.Now(), t.DNSMs, t.ConnectMs, t.TLSMs, t.TTFBMs, t.TransferMs, result.Attempts,
+		result.StatusCode, result.Up, result.ErrorClass, result.ErrorMessage)
 	return err
 }
 
-// DeleteProbeHistory membersihkan history saat URL dihapus
+// CleanupProbeHistory menghapus history yang lebih tua dari masa retensi:
+// retention_days milik target jika diisi, selain itu globalDays. Run log
+// scheduler dan log pengiriman notifikasi ikut dibersihkan dengan globalDays.
+// Mengembalikan jumlah baris history yang dihapus.
+func (s *Store) CleanupProbeHistory(globalDays int) (int64, error) {
+	now := time.Now()
+	globalCutoff := now.AddDate(0, 0, -globalDays)
+
+	res, err := s.Db.Exec(`DELETE FROM probe_history WHERE timestamp < ?
+		AND url_id NOT IN (SELECT id FROM urls WHERE retention_days > 0)`, globalCutoff)
+	if err != nil {
+		return 0, err
+	}
+	deleted, _ := res.RowsAffected()
+
+	rows, err := s.Db.Query("SELECT id, retention_days FROM urls WHERE retention_days > 0")
+	if err != nil {
+		return deleted, err
+	}
+	custom := map[int]int{}
+	for rows.Next() {
+		var id, days int
+		if err := rows.Scan(&id, &days); err != nil {
+			rows.Close()
+			return deleted, err
+		}
+		custom[id] = days
+	}
+	rows.Close()
+
+	for id, days := range custom {
+		res, err := s.Db.Exec("DELETE FROM probe_history WHERE url_id = ? AND timestamp < ?", id, now.AddDate(0, 0, -days))
+		if err != nil {
+			return deleted, err
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+
+	_, err = s.Db.Exec(`DELETE FROM scheduler_runs WHERE started_at < ?;
+		DELETE FROM notification_deliveries WHERE created_at < ?`, globalCutoff, globalCutoff)
+	return deleted, err
+}
+
+// DeleteProbeHistory membersihkan history (termasuk rollup dan incident) saat
+// URL dihapus
 func (s *Store) DeleteProbeHistory(urlID int) error {
-	_, err := s.Db.Exec("DELETE FROM probe_history WHERE url_id = ?", urlID)
+	_, err := s.Db.Exec(`DELETE FROM probe_history WHERE url_id = ?;
+		DELETE FROM probe_rollup_hourly WHERE url_id = ?;
+		DELETE FROM probe_rollup_daily WHERE url_id = ?;
+		DELETE FROM incidents WHERE url_id = ?`, urlID, urlID, urlID, urlID)
 	return err
 }
 
@@ -163,56 +715,36 @@ func (s *Store) DeleteProbeHistory(urlID int) error {
 func (s *Store) GetProbeHistory(urlID int, limit int) ([]models.ProbeHistory, error) {
 	// Diperbarui: Menggunakan JOIN untuk mengambil urls.url
 	rows, err := s.Db.Query(`
-		SELECT h.url_id, u.url, h.latency_ms, h.timestamp 
+		SELECT `+historyColumns+`
 		FROM probe_history h
 		JOIN urls u ON h.url_id = u.id
-		WHERE h.url_id = ? 
-		ORDER BY h.timestamp DESC 
+		WHERE h.url_id = ?
+		ORDER BY h.timestamp DESC
 		LIMIT ?`, urlID, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var history []models.ProbeHistory
-	for rows.Next() {
-		var h models.ProbeHistory
-		if err := rows.Scan(&h.URLID, &h.URL, &h.LatencyMs, &h.Timestamp); err != nil {
-			return nil, err
-		}
-		history = append(history, h)
-	}
-	return history, nil
+	return scanHistoryRows(rows)
 }
 
 // GetAllProbeHistory mengambil N probe terakhir dari SEMUA URL (untuk Scheduler)
 func (s *Store) GetAllProbeHistory(limit int) ([]models.ProbeHistory, error) {
 	rows, err := s.Db.Query(`
-        SELECT h.url_id, u.url, h.latency_ms, h.timestamp 
+        SELECT `+historyColumns+`
         FROM probe_history h
         JOIN urls u ON h.url_id = u.id
-        ORDER BY h.timestamp DESC 
+        ORDER BY h.timestamp DESC
         LIMIT ?`, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var history []models.ProbeHistory
-	for rows.Next() {
-		var h models.ProbeHistory
-		if err := rows.Scan(&h.URLID, &h.URL, &h.LatencyMs, &h.Timestamp); err != nil {
-			return nil, err
-		}
-		history = append(history, h)
-	}
-	return history, nil
+	return scanHistoryRows(rows)
 }
 
 // GetAllProbeHistoryPaged mengambil probe_history dengan limit dan offset (untuk pagination)
 func (s *Store) GetAllProbeHistoryPaged(limit int, offset int) ([]models.ProbeHistory, error) {
 	rows, err := s.Db.Query(`
-        SELECT h.url_id, u.url, h.latency_ms, h.timestamp
+        SELECT `+historyColumns+`
         FROM probe_history h
         JOIN urls u ON h.url_id = u.id
         ORDER BY h.timestamp DESC
@@ -220,17 +752,7 @@ func (s *Store) GetAllProbeHistoryPaged(limit int, offset int) ([]models.ProbeHi
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var history []models.ProbeHistory
-	for rows.Next() {
-		var h models.ProbeHistory
-		if err := rows.Scan(&h.URLID, &h.URL, &h.LatencyMs, &h.Timestamp); err != nil {
-			return nil, err
-		}
-		history = append(history, h)
-	}
-	return history, nil
+	return scanHistoryRows(rows)
 }
 
 // CountProbeHistory menghitung total baris probe_history
@@ -243,7 +765,7 @@ func (s *Store) CountProbeHistory() (int64, error) {
 // GetProbeHistoryByRange mengambil probe untuk SATU URL dalam interval waktu tertentu (ASC)
 func (s *Store) GetProbeHistoryByRange(urlID int, since time.Time) ([]models.ProbeHistory, error) {
 	rows, err := s.Db.Query(`
-		SELECT h.url_id, u.url, h.latency_ms, h.timestamp 
+		SELECT `+historyColumns+`
 		FROM probe_history h
 		JOIN urls u ON h.url_id = u.id
 		WHERE h.url_id = ? AND h.timestamp >= ?
@@ -252,15 +774,66 @@ func (s *Store) GetProbeHistoryByRange(urlID int, since time.Time) ([]models.Pro
 	if err != nil {
 		return nil, err
 	}
+	return scanHistoryRows(rows)
+}
+
+// --- FUNGSI SCHEDULER RUNS ---
+
+// AddSchedulerRun mencatat satu eksekusi job scheduler (termasuk yang dilewati)
+func (s *Store) AddSchedulerRun(run models.SchedulerRun) error {
+	var urlID sql.NullInt64
+	if run.URLID != 0 {
+		urlID = sql.NullInt64{Int64: int64(run.URLID), Valid: true}
+	}
+	_, err := s.Db.Exec(`INSERT INTO scheduler_runs (scope, url_id, started_at, finished_at, targets, failures, skipped, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Scope, urlID, run.StartedAt, run.FinishedAt, run.Targets, run.Failures, run.Skipped, run.Error)
+	return err
+}
+
+// schedulerRunColumns adalah kolom yang dibaca oleh scanSchedulerRuns
+const schedulerRunColumns = `r.id, r.scope, COALESCE(r.url_id, 0), COALESCE(u.url, ''),
+	r.started_at, r.finished_at, r.targets, r.failures, r.skipped, r.error`
+
+// GetSchedulerRuns mengambil run scheduler terbaru (untuk run log)
+func (s *Store) GetSchedulerRuns(limit int) ([]models.SchedulerRun, error) {
+	rows, err := s.Db.Query(`SELECT `+schedulerRunColumns+`
+		FROM scheduler_runs r LEFT JOIN urls u ON r.url_id = u.id
+		ORDER BY r.started_at DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSchedulerRuns(rows)
+}
+
+// GetLastSchedulerRun mengambil run global terakhir yang tidak dilewati.
+// Mengembalikan SchedulerRun kosong jika belum ada.
+func (s *Store) GetLastSchedulerRun() (models.SchedulerRun, error) {
+	rows, err := s.Db.Query(`SELECT `+schedulerRunColumns+`
+		FROM scheduler_runs r LEFT JOIN urls u ON r.url_id = u.id
+		WHERE r.scope = ? AND r.skipped = 0
+		ORDER BY r.started_at DESC LIMIT 1`, models.RunScopeGlobal)
+	if err != nil {
+		return models.SchedulerRun{}, err
+	}
+	runs, err := scanSchedulerRuns(rows)
+	if err != nil || len(runs) == 0 {
+		return models.SchedulerRun{}, err
+	}
+	return runs[0], nil
+}
+
+func scanSchedulerRuns(rows *sql.Rows) ([]models.SchedulerRun, error) {
 	defer rows.Close()
 
-	var history []models.ProbeHistory
+	var runs []models.SchedulerRun
 	for rows.Next() {
-		var h models.ProbeHistory
-		if err := rows.Scan(&h.URLID, &h.URL, &h.LatencyMs, &h.Timestamp); err != nil {
+		var r models.SchedulerRun
+		if err := rows.Scan(&r.ID, &r.Scope, &r.URLID, &r.URL,
+			&r.StartedAt, &r.FinishedAt, &r.Targets, &r.Failures, &r.Skipped, &r.Error); err != nil {
 			return nil, err
 		}
-		history = append(history, h)
+		runs = append(runs, r)
 	}
-	return history, nil
+	return runs, rows.Err()
 }
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+	"test/models"
+	"time"
+)
+
+// incidentColumns adalah kolom yang dibaca oleh scanIncidents (alias i =
+// incidents, u = urls)
+const incidentColumns = `i.id, i.url_id, COALESCE(u.url, ''), i.started_at, i.ended_at,
+	i.status_code, i.error_class, i.error_message, i.probe_count`
+
+// OpenIncident membuka incident untuk target yang baru dikonfirmasi down.
+// failedRuns adalah jumlah run gagal berturut-turut (termasuk run ini yang
+// sudah dicatat di history), sehingga awal incident adalah waktu run gagal
+// pertama, bukan waktu konfirmasi. Tidak melakukan apa-apa jika target
+// masih punya incident yang terbuka.
+func (s *Store) OpenIncident(urlID int, result models.ProbeOutcome, failedRuns int) error {
+	if failedRuns < 1 {
+		failedRuns = 1
+	}
+	startedAt := time.Now()
+	err := s.Db.QueryRow(`SELECT timestamp FROM probe_history WHERE url_id = ?
+		ORDER BY timestamp DESC LIMIT 1 OFFSET ?`, urlID, failedRuns-1).Scan(&startedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	_, err = s.Db.Exec(`INSERT INTO incidents (url_id, started_at, status_code, error_class, error_message, probe_count)
+		SELECT ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (SELECT 1 FROM incidents WHERE url_id = ? AND ended_at IS NULL)`,
+		urlID, startedAt, result.StatusCode, result.ErrorClass, result.ErrorMessage, failedRuns, urlID)
+	return err
+}
+
+// CountIncidentProbe menambah jumlah run gagal pada incident yang terbuka
+func (s *Store) CountIncidentProbe(urlID int) error {
+	_, err := s.Db.Exec("UPDATE incidents SET probe_count = probe_count + 1 WHERE url_id = ? AND ended_at IS NULL", urlID)
+	return err
+}
+
+// CloseIncident menutup incident yang terbuka saat target pulih.
+// Mengembalikan false jika target tidak punya incident yang terbuka (mis.
+// target baru yang belum pernah down).
+func (s *Store) CloseIncident(urlID int, endedAt time.Time) (bool, error) {
+	res, err := s.Db.Exec("UPDATE incidents SET ended_at = ? WHERE url_id = ? AND ended_at IS NULL", endedAt, urlID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetIncidents mengambil incident terbaru, untuk satu target atau semua
+// target jika urlID 0. Incident yang masih terbuka selalu di atas.
+func (s *Store) GetIncidents(urlID int, limit int) ([]models.Incident, error) {
+	query := `SELECT ` + incidentColumns + `
+		FROM incidents i LEFT JOIN urls u ON i.url_id = u.id`
+	var args []any
+	if urlID > 0 {
+		query += " WHERE i.url_id = ?"
+		args = append(args, urlID)
+	}
+	query += " ORDER BY i.ended_at IS NOT NULL, i.started_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.Db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanIncidents(rows)
+}
+
+func scanIncidents(rows *sql.Rows) ([]models.Incident, error) {
+	defer rows.Close()
+
+	var incidents []models.Incident
+	for rows.Next() {
+		var i models.Incident
+		if err := rows.Scan(&i.ID, &i.URLID, &i.URL, &i.StartedAt, &i.EndedAt,
+			&i.StatusCode, &i.ErrorClass, &i.ErrorMessage, &i.ProbeCount); err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, i)
+	}
+	return incidents, rows.Err()
+}
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"test/models"
+	"time"
+)
+
+// channelColumns adalah kolom yang dibaca oleh scanChannels
+const channelColumns = `id, name, type, url, secret, enabled, created_at`
+
+// AddChannel menyimpan channel notifikasi baru dan mengembalikan ID-nya
+func (s *Store) AddChannel(c models.NotificationChannel) (int, error) {
+	res, err := s.Db.Exec(`INSERT INTO notification_channels (name, type, url, secret, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`, c.Name, c.Type, c.URL, c.Secret, c.Enabled, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+// GetChannels mengambil semua channel notifikasi, atau hanya yang aktif
+func (s *Store) GetChannels(enabledOnly bool) ([]models.NotificationChannel, error) {
+	query := "SELECT " + channelColumns + " FROM notification_channels"
+	if enabledOnly {
+		query += " WHERE enabled = 1"
+	}
+	rows, err := s.Db.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	return scanChannels(rows)
+}
+
+// GetChannel mengambil satu channel berdasarkan ID. Mengembalikan
+// sql.ErrNoRows jika tidak ada.
+func (s *Store) GetChannel(id int) (models.NotificationChannel, error) {
+	rows, err := s.Db.Query("SELECT "+channelColumns+" FROM notification_channels WHERE id = ?", id)
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+	channels, err := scanChannels(rows)
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+	if len(channels) == 0 {
+		return models.NotificationChannel{}, sql.ErrNoRows
+	}
+	return channels[0], nil
+}
+
+// DeleteChannel menghapus channel beserta log pengirimannya
+func (s *Store) DeleteChannel(id int) error {
+	_, err := s.Db.Exec(`DELETE FROM notification_deliveries WHERE channel_id = ?;
+		DELETE FROM notification_channels WHERE id = ?`, id, id)
+	return err
+}
+
+func scanChannels(rows *sql.Rows) ([]models.NotificationChannel, error) {
+	defer rows.Close()
+
+	var channels []models.NotificationChannel
+	for rows.Next() {
+		var c models.NotificationChannel
+		if err := rows.Scan(&c.ID, &c.Name, &c.Type, &c.URL, &c.Secret, &c.Enabled, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		channels = append(channels, c)
+	}
+	return channels, rows.Err()
+}
+
+// AddDelivery mencatat hasil pengiriman satu alert ke satu channel
+func (s *Store) AddDelivery(d models.NotificationDelivery) error {
+	_, err := s.Db.Exec(`INSERT INTO notification_deliveries
+		(channel_id, url_id, event, success, status_code, attempts, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ChannelID, d.URLID, d.Event, d.Success, d.StatusCode, d.Attempts, d.Error, d.CreatedAt)
+	return err
+}
+
+// GetDeliveries mengambil log pengiriman terbaru
+func (s *Store) GetDeliveries(limit int) ([]models.NotificationDelivery, error) {
+	rows, err := s.Db.Query(`SELECT d.id, d.channel_id, COALESCE(c.name, ''), d.url_id, COALESCE(u.url, ''),
+			d.event, d.success, d.status_code, d.attempts, d.error, d.created_at
+		FROM notification_deliveries d
+		LEFT JOIN notification_channels c ON d.channel_id = c.id
+		LEFT JOIN urls u ON d.url_id = u.id
+		ORDER BY d.created_at DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.NotificationDelivery
+	for rows.Next() {
+		var d models.NotificationDelivery
+		if err := rows.Scan(&d.ID, &d.ChannelID, &d.ChannelName, &d.URLID, &d.URL,
+			&d.Event, &d.Success, &d.StatusCode, &d.Attempts, &d.Error, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// GetSMTPConfig membaca pengaturan SMTP dari tabel settings
+func (s *Store) GetSMTPConfig() (models.SMTPConfig, error) {
+	rows, err := s.Db.Query("SELECT key, value FROM settings WHERE key LIKE 'smtp_%'")
+	if err != nil {
+		return models.SMTPConfig{}, err
+	}
+	defer rows.Close()
+
+	values := map[string]string{}
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return models.SMTPConfig{}, err
+		}
+		values[key] = value.String
+	}
+	if err := rows.Err(); err != nil {
+		return models.SMTPConfig{}, err
+	}
+
+	cfg := models.SMTPConfig{
+		Host:     values["smtp_host"],
+		Port:     models.DefaultSMTPPort,
+		StartTLS: values["smtp_starttls"] != "0",
+		Username: values["smtp_username"],
+		Password: values["smtp_password"],
+		From:     values["smtp_from"],
+		To:       models.SplitAddresses(values["smtp_to"]),
+	}
+	if port, err := strconv.Atoi(values["smtp_port"]); err == nil && port > 0 {
+		cfg.Port = port
+	}
+	return cfg, nil
+}
+
+// SaveSMTPConfig menyimpan pengaturan SMTP ke tabel settings
+func (s *Store) SaveSMTPConfig(cfg models.SMTPConfig) error {
+	startTLS := "0"
+	if cfg.StartTLS {
+		startTLS = "1"
+	}
+	values := map[string]string{
+		"smtp_host":     cfg.Host,
+		"smtp_port":     strconv.Itoa(cfg.Port),
+		"smtp_starttls": startTLS,
+		"smtp_username": cfg.Username,
+		"smtp_password": cfg.Password,
+		"smtp_from":     cfg.From,
+		"smtp_to":       strings.Join(cfg.To, ","),
+	}
+	for key, value := range values {
+		if err := s.SetSetting(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"test/models"
+	"time"
+)
+
+// rollupLevel menjelaskan satu tabel rollup, cara membagi waktu ke bucket
+// dan berapa lama rollup disimpan
+type rollupLevel struct {
+	table     string
+	truncate  func(time.Time) time.Time
+	next      func(time.Time) time.Time
+	retention time.Duration
+}
+
+var rollupLevels = map[string]rollupLevel{
+	models.ResolutionHourly: {
+		table:    "probe_rollup_hourly",
+		truncate: func(t time.Time) time.Time { return t.Truncate(time.Hour) },
+		next:     func(t time.Time) time.Time { return t.Add(time.Hour) },
+		// Cukup untuk jendela availability terpanjang (90 hari)
+		retention: 92 * 24 * time.Hour,
+	},
+	models.ResolutionDaily: {
+		table: "probe_rollup_daily",
+		truncate: func(t time.Time) time.Time {
+			y, m, d := t.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		},
+		next:      func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+		retention: 2 * 365 * 24 * time.Hour,
+	},
+}
+
+// rollupRecheck adalah rentang bucket yang sudah selesai tapi tetap
+// dirangkum ulang, untuk menangkap probe yang tercatat sesaat setelah
+// bucketnya dirangkum. Job maintenance berjalan tiap jam, jadi setiap bucket
+// dirangkum paling sedikit dua kali.
+const rollupRecheck = 2 * time.Hour
+
+// rollupSample adalah satu probe mentah yang akan dirangkum
+type rollupSample struct {
+	urlID     int
+	latencyMs int64
+	isUp      bool
+	timestamp time.Time
+}
+
+// UpdateRollups mengisi tabel rollup per jam dan per hari secara
+// inkremental: bucket yang sudah lengkap dan belum pernah dirangkum, ditambah
+// bucket yang baru selesai (lihat rollupRecheck).
+func (s *Store) UpdateRollups(now time.Time) error {
+	for _, resolution := range []string{models.ResolutionHourly, models.ResolutionDaily} {
+		if err := s.updateRollup(rollupLevels[resolution], now); err != nil {
+			return fmt.Errorf("rollup %s: %w", resolution, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) updateRollup(level rollupLevel, now time.Time) error {
+	// Lanjutkan dari bucket terakhir, atau dari history tertua jika tabel kosong
+	var last time.Time
+	err := s.Db.QueryRow("SELECT bucket FROM " + level.table + " ORDER BY bucket DESC LIMIT 1").Scan(&last)
+	var start time.Time
+	switch {
+	case err == nil:
+		start = level.next(last.In(now.Location()))
+	case err == sql.ErrNoRows:
+		var oldest time.Time
+		err = s.Db.QueryRow("SELECT timestamp FROM probe_history ORDER BY timestamp LIMIT 1").Scan(&oldest)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start = level.truncate(oldest.In(now.Location()))
+	default:
+		return err
+	}
+
+	if recheck := level.truncate(now.Add(-rollupRecheck)); recheck.Before(start) {
+		start = recheck
+	}
+
+	// Bucket yang sedang berjalan belum lengkap, dirangkum di run berikutnya
+	end := level.truncate(now)
+	for bucket := start; bucket.Before(end); bucket = level.next(bucket) {
+		samples, err := s.rollupSamples(0, bucket, level.next(bucket))
+		if err != nil {
+			return err
+		}
+		for _, r := range summarizeBuckets(samples, level.truncate) {
+			if err := s.saveRollup(level.table, r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CleanupRollups menghapus rollup yang melewati masa simpan tiap resolusi.
+// Mengembalikan jumlah baris yang dihapus.
+func (s *Store) CleanupRollups(now time.Time) (int64, error) {
+	var deleted int64
+	for _, level := range rollupLevels {
+		res, err := s.Db.Exec("DELETE FROM "+level.table+" WHERE bucket < ?", now.Add(-level.retention))
+		if err != nil {
+			return deleted, err
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// rollupSamples membaca probe mentah dalam rentang [from, to), untuk satu
+// target atau semua target jika urlID 0
+func (s *Store) rollupSamples(urlID int, from, to time.Time) ([]rollupSample, error) {
+	query := "SELECT url_id, latency_ms, is_up, timestamp FROM probe_history WHERE timestamp >= ? AND timestamp < ?"
+	args := []any{from, to}
+	if urlID > 0 {
+		query += " AND url_id = ?"
+		args = append(args, urlID)
+	}
+	rows, err := s.Db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []rollupSample
+	for rows.Next() {
+		var sample rollupSample
+		if err := rows.Scan(&sample.urlID, &sample.latencyMs, &sample.isUp, &sample.timestamp); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, rows.Err()
+}
+
+// summarizeBuckets mengelompokkan probe per target dan per bucket lalu
+// menghitung statistiknya. Hasil terurut menurut bucket.
+func summarizeBuckets(samples []rollupSample, truncate func(time.Time) time.Time) []models.ProbeRollup {
+	type key struct {
+		urlID  int
+		bucket int64
+	}
+	groups := map[key]*models.ProbeRollup{}
+	latencies := map[key][]int64{}
+	var order []key
+
+	for _, sample := range samples {
+		bucket := truncate(sample.timestamp)
+		k := key{sample.urlID, bucket.Unix()}
+		r, ok := groups[k]
+		if !ok {
+			r = &models.ProbeRollup{URLID: sample.urlID, Bucket: bucket}
+			groups[k] = r
+			order = append(order, k)
+		}
+		r.Count++
+		if !sample.isUp {
+			r.Failures++
+			continue
+		}
+		latencies[k] = append(latencies[k], sample.latencyMs)
+	}
+
+	result := make([]models.ProbeRollup, 0, len(order))
+	for _, k := range order {
+		r := groups[k]
+		sum := summarizeLatencies(latencies[k])
+		r.MinMs, r.AvgMs, r.MaxMs = sum.Min, sum.Avg, sum.Max
+		r.P50Ms, r.P90Ms, r.P95Ms, r.P99Ms = sum.P50, sum.P90, sum.P95, sum.P99
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Bucket.Equal(result[j].Bucket) {
+			return result[i].Bucket.Before(result[j].Bucket)
+		}
+		return result[i].URLID < result[j].URLID
+	})
+	return result
+}
+
+func (s *Store) saveRollup(table string, r models.ProbeRollup) error {
+	_, err := s.Db.Exec(`INSERT OR REPLACE INTO `+table+`
+		(url_id, bucket, count, failures, min_ms, avg_ms, max_ms, p50_ms, p90_ms, p95_ms, p99_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.URLID, r.Bucket, r.Count, r.Failures, r.MinMs, r.AvgMs, r.MaxMs, r.P50Ms, r.P90Ms, r.P95Ms, r.P99Ms)
+	return err
+}
+
+// GetRollups mengambil rollup satu target sejak waktu tertentu. Bucket yang
+// belum dirangkum (termasuk bucket yang sedang berjalan) dihitung langsung
+// dari history mentah agar chart selalu sampai data terbaru.
+func (s *Store) GetRollups(urlID int, resolution string, since time.Time) ([]models.ProbeRollup, error) {
+	level, ok := rollupLevels[resolution]
+	if !ok {
+		return nil, fmt.Errorf("resolusi rollup tidak dikenal: %q", resolution)
+	}
+	since = level.truncate(since)
+
+	rows, err := s.Db.Query(`SELECT url_id, bucket, count, failures, min_ms, avg_ms, max_ms, p50_ms, p90_ms, p95_ms, p99_ms
+		FROM `+level.table+` WHERE url_id = ? AND bucket >= ? ORDER BY bucket ASC`, urlID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []models.ProbeRollup
+	for rows.Next() {
+		var r models.ProbeRollup
+		if err := rows.Scan(&r.URLID, &r.Bucket, &r.Count, &r.Failures,
+			&r.MinMs, &r.AvgMs, &r.MaxMs, &r.P50Ms, &r.P90Ms, &r.P95Ms, &r.P99Ms); err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tailStart := since
+	if len(rollups) > 0 {
+		tailStart = level.next(rollups[len(rollups)-1].Bucket.In(since.Location()))
+	}
+	samples, err := s.rollupSamples(urlID, tailStart, time.Now().Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	return append(rollups, summarizeBuckets(samples, level.truncate)...), nil
+}
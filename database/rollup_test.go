@@ -0,0 +1,83 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store := NewStore(filepath.Join(t.TempDir(), "probe.db"))
+	t.Cleanup(func() { store.Db.Close() })
+	return store
+}
+
+func addHistoryAt(t *testing.T, s *Store, urlID int, ts time.Time, up bool) {
+	t.Helper()
+	_, err := s.Db.Exec(`INSERT INTO probe_history (url_id, latency_ms, timestamp, status_code, is_up)
+		VALUES (?, 100, ?, 200, ?)`, urlID, ts, up)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hourlyCount(t *testing.T, s *Store, urlID int, bucket time.Time) (count, failures int64) {
+	t.Helper()
+	err := s.Db.QueryRow("SELECT count, failures FROM probe_rollup_hourly WHERE url_id = ? AND bucket = ?",
+		urlID, bucket).Scan(&count, &failures)
+	if err != nil {
+		t.Fatalf("rollup %d @ %s: %v", urlID, bucket.Format(time.TimeOnly), err)
+	}
+	return count, failures
+}
+
+func TestUpdateRollupsPicksUpLateProbes(t *testing.T) {
+	s := newTestStore(t)
+	bucket := time.Date(2026, 3, 10, 10, 0, 0, 0, time.Local)
+	addHistoryAt(t, s, 1, bucket.Add(30*time.Minute), true)
+	addHistoryAt(t, s, 2, bucket.Add(40*time.Minute), true)
+
+	if err := s.UpdateRollups(bucket.Add(time.Hour + time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := hourlyCount(t, s, 2, bucket); count != 1 {
+		t.Fatalf("count = %d before late probe, want 1", count)
+	}
+
+	// Probe yang tercatat setelah bucket-nya dirangkum
+	addHistoryAt(t, s, 2, bucket.Add(59*time.Minute), false)
+	if err := s.UpdateRollups(bucket.Add(2*time.Hour + time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if count, failures := hourlyCount(t, s, 2, bucket); count != 2 || failures != 1 {
+		t.Errorf("target 2 = %d probes, %d failures, want 2 and 1", count, failures)
+	}
+	if count, _ := hourlyCount(t, s, 1, bucket); count != 1 {
+		t.Errorf("target 1 = %d probes, want 1", count)
+	}
+}
+
+func TestCleanupRollups(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Date(2026, 3, 10, 10, 0, 0, 0, time.Local)
+	for _, age := range []time.Duration{24 * time.Hour, 100 * 24 * time.Hour, 800 * 24 * time.Hour} {
+		addHistoryAt(t, s, 1, now.Add(-age), true)
+	}
+	if err := s.UpdateRollups(now); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CleanupRollups(now); err != nil {
+		t.Fatal(err)
+	}
+	for table, want := range map[string]int{"probe_rollup_hourly": 1, "probe_rollup_daily": 2} {
+		var n int
+		if err := s.Db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Errorf("%s has %d rows after cleanup, want %d", table, n, want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"math"
+	"sort"
+	"test/models"
+	"time"
+)
+
+// latencySummary adalah statistik dari sekumpulan latency (milidetik)
+type latencySummary struct {
+	Min, Avg, Max      int64
+	P50, P90, P95, P99 int64
+}
+
+// summarizeLatencies menghitung min/avg/max dan persentil. Slice diurutkan
+// di tempat.
+func summarizeLatencies(latencies []int64) latencySummary {
+	if len(latencies) == 0 {
+		return latencySummary{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	var sum int64
+	for _, l := range latencies {
+		sum += l
+	}
+	return latencySummary{
+		Min: latencies[0],
+		Avg: sum / int64(len(latencies)),
+		Max: latencies[len(latencies)-1],
+		P50: percentile(latencies, 50),
+		P90: percentile(latencies, 90),
+		P95: percentile(latencies, 95),
+		P99: percentile(latencies, 99),
+	}
+}
+
+// percentile mengembalikan persentil p (0-100) dari slice yang sudah
+// terurut dengan metode nearest-rank
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// rawPercentileWindow adalah jendela terpanjang yang persentilnya dihitung
+// persis dari history mentah. Jendela yang lebih panjang memakai rollup per
+// jam agar dashboard tidak memuat seluruh history ke memori.
+const rawPercentileWindow = 24 * time.Hour
+
+// GetLatencyPercentiles menghitung persentil latency per target dan global
+// dari probe yang up sejak waktu tertentu. Probe yang gagal tidak dihitung
+// agar timeout tidak mengaburkan latency normal.
+func (s *Store) GetLatencyPercentiles(since time.Time) (map[int]models.LatencyPercentiles, models.LatencyPercentiles, error) {
+	if time.Since(since) > rawPercentileWindow {
+		return s.rollupLatencyPercentiles(since)
+	}
+
+	rows, err := s.Db.Query(`
+		SELECT url_id, latency_ms
+		FROM probe_history
+		WHERE timestamp >= ? AND is_up = 1`, since)
+	if err != nil {
+		return nil, models.LatencyPercentiles{}, err
+	}
+	defer rows.Close()
+
+	perTarget := make(map[int][]int64)
+	var all []int64
+	for rows.Next() {
+		var urlID int
+		var latency int64
+		if err := rows.Scan(&urlID, &latency); err != nil {
+			return nil, models.LatencyPercentiles{}, err
+		}
+		perTarget[urlID] = append(perTarget[urlID], latency)
+		all = append(all, latency)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.LatencyPercentiles{}, err
+	}
+
+	result := make(map[int]models.LatencyPercentiles, len(perTarget))
+	for urlID, latencies := range perTarget {
+		result[urlID] = toPercentiles(latencies)
+	}
+	return result, toPercentiles(all), nil
+}
+
+// latencyPoint adalah persentil satu bucket rollup (atau satu probe mentah)
+// dengan bobot jumlah probe up di dalamnya
+type latencyPoint struct {
+	p50, p90, p95, p99 int64
+	weight             int64
+}
+
+// rollupLatencyPercentiles memperkirakan persentil dari rollup per jam:
+// persentil setiap bucket digabung dengan bobot jumlah probe up. Bagian yang
+// belum dirangkum diambil dari history mentah.
+func (s *Store) rollupLatencyPercentiles(since time.Time) (map[int]models.LatencyPercentiles, models.LatencyPercentiles, error) {
+	level := rollupLevels[models.ResolutionHourly]
+	since = level.truncate(since)
+
+	rows, err := s.Db.Query(`
+		SELECT url_id, bucket, count - failures, p50_ms, p90_ms, p95_ms, p99_ms
+		FROM `+level.table+`
+		WHERE bucket >= ? AND count > failures`, since)
+	if err != nil {
+		return nil, models.LatencyPercentiles{}, err
+	}
+	defer rows.Close()
+
+	perTarget := make(map[int][]latencyPoint)
+	var all []latencyPoint
+	coveredUntil := since
+	for rows.Next() {
+		var urlID int
+		var bucket time.Time
+		var p latencyPoint
+		if err := rows.Scan(&urlID, &bucket, &p.weight, &p.p50, &p.p90, &p.p95, &p.p99); err != nil {
+			return nil, models.LatencyPercentiles{}, err
+		}
+		perTarget[urlID] = append(perTarget[urlID], p)
+		all = append(all, p)
+		if end := level.next(bucket.In(since.Location())); end.After(coveredUntil) {
+			coveredUntil = end
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.LatencyPercentiles{}, err
+	}
+
+	raw, err := s.Db.Query(`
+		SELECT url_id, latency_ms
+		FROM probe_history
+		WHERE timestamp >= ? AND is_up = 1`, coveredUntil)
+	if err != nil {
+		return nil, models.LatencyPercentiles{}, err
+	}
+	defer raw.Close()
+	for raw.Next() {
+		var urlID int
+		var latency int64
+		if err := raw.Scan(&urlID, &latency); err != nil {
+			return nil, models.LatencyPercentiles{}, err
+		}
+		p := latencyPoint{p50: latency, p90: latency, p95: latency, p99: latency, weight: 1}
+		perTarget[urlID] = append(perTarget[urlID], p)
+		all = append(all, p)
+	}
+	if err := raw.Err(); err != nil {
+		return nil, models.LatencyPercentiles{}, err
+	}
+
+	result := make(map[int]models.LatencyPercentiles, len(perTarget))
+	for urlID, points := range perTarget {
+		result[urlID] = mergePercentiles(points)
+	}
+	return result, mergePercentiles(all), nil
+}
+
+// mergePercentiles menggabungkan persentil beberapa bucket menjadi satu
+// perkiraan: persentil p diambil dari persentil p setiap bucket dengan
+// metode nearest-rank berbobot
+func mergePercentiles(points []latencyPoint) models.LatencyPercentiles {
+	var total int64
+	for _, p := range points {
+		total += p.weight
+	}
+	result := models.LatencyPercentiles{Count: int(total), Approximate: true}
+	if total == 0 {
+		return result
+	}
+
+	weighted := func(value func(latencyPoint) int64, pct float64) int64 {
+		sort.Slice(points, func(i, j int) bool { return value(points[i]) < value(points[j]) })
+		rank := int64(math.Ceil(pct / 100 * float64(total)))
+		var seen int64
+		for _, p := range points {
+			seen += p.weight
+			if seen >= rank {
+				return value(p)
+			}
+		}
+		return value(points[len(points)-1])
+	}
+	result.P50 = weighted(func(p latencyPoint) int64 { return p.p50 }, 50)
+	result.P90 = weighted(func(p latencyPoint) int64 { return p.p90 }, 90)
+	result.P95 = weighted(func(p latencyPoint) int64 { return p.p95 }, 95)
+	result.P99 = weighted(func(p latencyPoint) int64 { return p.p99 }, 99)
+	return result
+}
+
+// toPercentiles merangkum latency menjadi models.LatencyPercentiles
+func toPercentiles(latencies []int64) models.LatencyPercentiles {
+	summary := summarizeLatencies(latencies)
+	return models.LatencyPercentiles{
+		Count: len(latencies),
+		P50:   summary.P50,
+		P90:   summary.P90,
+		P95:   summary.P95,
+		P99:   summary.P99,
+	}
+}
+
+// GetRecentLatencies mengambil latency dari limit probe up terakhir milik
+// target, terbaru lebih dulu
+func (s *Store) GetRecentLatencies(urlID, limit int) ([]int64, error) {
+	rows, err := s.Db.Query(`
+		SELECT latency_ms
+		FROM probe_history
+		WHERE url_id = ? AND is_up = 1
+		ORDER BY timestamp DESC
+		LIMIT ?`, urlID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var latencies []int64
+	for rows.Next() {
+		var latency int64
+		if err := rows.Scan(&latency); err != nil {
+			return nil, err
+		}
+		latencies = append(latencies, latency)
+	}
+	return latencies, rows.Err()
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func addLatencyAt(t *testing.T, s *Store, urlID int, ts time.Time, latencyMs int64) {
+	t.Helper()
+	_, err := s.Db.Exec(`INSERT INTO probe_history (url_id, latency_ms, timestamp, status_code, is_up)
+		VALUES (?, ?, ?, 200, 1)`, urlID, latencyMs, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLatencyPercentilesLongWindowUsesRollups(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	old := now.Add(-72 * time.Hour).Truncate(time.Hour)
+	for i := 0; i < 9; i++ {
+		addLatencyAt(t, s, 1, old.Add(time.Duration(i)*time.Minute), 100)
+	}
+	addHistoryAt(t, s, 1, old.Add(30*time.Minute), false)
+	addLatencyAt(t, s, 1, old.Add(time.Hour), 1000)
+	if err := s.UpdateRollups(now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hapus history yang sudah dirangkum: jendela panjang harus tetap terisi
+	// dari rollup, bukan dari baris mentah
+	if _, err := s.Db.Exec("DELETE FROM probe_history"); err != nil {
+		t.Fatal(err)
+	}
+	addLatencyAt(t, s, 1, now, 50)
+
+	perTarget, global, err := s.GetLatencyPercentiles(now.Add(-7 * 24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !global.Approximate {
+		t.Error("Approximate = false for a 7d window")
+	}
+	if global.Count != 11 || global.P50 != 100 || global.P99 != 1000 {
+		t.Errorf("global = %+v, want count 11, p50 100, p99 1000", global)
+	}
+	if got := perTarget[1]; got.Count != 11 || got.P50 != 100 {
+		t.Errorf("target 1 = %+v, want count 11, p50 100", got)
+	}
+
+	_, recent, err := s.GetLatencyPercentiles(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recent.Approximate || recent.Count != 1 || recent.P50 != 50 {
+		t.Errorf("1h window = %+v, want exact count 1, p50 50", recent)
+	}
+}
@@ -1,26 +1,31 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/mail"
 	"strconv"
 	"strings"
 	"test/database"
 	"test/models"
+	"test/notify"
+	"test/probe"
 	"test/scheduler"
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/robfig/cron/v3"
 )
 
 type Application struct {
 	Store     *database.Store
 	Templates *template.Template
-	Scheduler *cron.Cron
-	JobID     cron.EntryID
+	Scheduler *scheduler.Scheduler
+	Notifier  *notify.Notifier
 }
 
 type Handlers struct {
@@ -56,8 +61,11 @@ func (h *Handlers) DashboardPage(w http.ResponseWriter, r *http.Request) {
 		selectedID = urls[0].ID
 	}
 
-	// Ambil data history probe (untuk chart)
+	// Ambil data history probe (untuk chart). Rentang panjang memakai rollup
+	// agar jumlah titik tetap kecil.
 	var historyData []models.ProbeHistory
+	var rollupData []models.ProbeRollup
+	resolution := models.ResolutionRaw
 	if selectedID > 0 {
 		qrange := r.URL.Query().Get("range")
 		var since time.Time
@@ -71,10 +79,17 @@ func (h *Handlers) DashboardPage(w http.ResponseWriter, r *http.Request) {
 			since = now.Add(-24 * time.Hour)
 		case "1w":
 			since = now.Add(-7 * 24 * time.Hour)
+			resolution = models.ResolutionHourly
 		case "1m":
 			since = now.Add(-30 * 24 * time.Hour)
+			resolution = models.ResolutionDaily
 		}
-		if !since.IsZero() {
+		if resolution != models.ResolutionRaw {
+			rollupData, err = h.App.Store.GetRollups(selectedID, resolution, since)
+			if err != nil {
+				log.Printf("Gagal mengambil data rollup: %v", err)
+			}
+		} else if !since.IsZero() {
 			historyData, err = h.App.Store.GetProbeHistoryByRange(selectedID, since)
 			if err != nil {
 				log.Printf("Gagal mengambil data history/Filter: %v", err)
@@ -89,9 +104,15 @@ func (h *Handlers) DashboardPage(w http.ResponseWriter, r *http.Request) {
 
 	// Siapkan PageData untuk dikirim ke template
 	urlActive := 0
+	var downURLs, degradedURLs []models.TargetURL
 	for _, u := range urls {
 		if u.IsUp {
 			urlActive++
+		} else {
+			downURLs = append(downURLs, u)
+		}
+		if u.IsDegraded {
+			degradedURLs = append(degradedURLs, u)
 		}
 	}
 	uptimePerc := 0
@@ -99,22 +120,55 @@ func (h *Handlers) DashboardPage(w http.ResponseWriter, r *http.Request) {
 		uptimePerc = int(100 * urlActive / len(urls))
 	}
 
+	// Persentil latency per target dan global dalam jendela yang dipilih
+	latencyWindow := r.URL.Query().Get("window")
+	windowDuration, ok := models.WindowDuration(latencyWindow)
+	if !ok {
+		latencyWindow = models.DefaultLatencyWindow
+		windowDuration, _ = models.WindowDuration(latencyWindow)
+	}
+	targetLatency, globalLatency, err := h.App.Store.GetLatencyPercentiles(time.Now().Add(-windowDuration))
+	if err != nil {
+		log.Printf("Gagal menghitung persentil latency: %v", err)
+	}
+
+	// Availability per target dan global (rollup + history mentah)
+	targetAvailability, globalAvailability, err := h.App.Store.GetAvailability(time.Now())
+	if err != nil {
+		log.Printf("Gagal menghitung availability: %v", err)
+	}
+
 	jsonHistory, _ := json.Marshal(historyData)
+	jsonRollups, _ := json.Marshal(rollupData)
 
 	data := models.PageData{
 		Page:             "dashboard",
 		URLs:             urls,
+		DownURLs:         downURLs,
+		DegradedURLs:     degradedURLs,
 		GlobalAvgLatency: calculateGlobalAvgLatency(urls),
 		LastCheckedTime:  getLatestProbeTime(urls),
 		HistoryData:      historyData,
 		SelectedURLID:    selectedID,
 		ChartRange:       r.URL.Query().Get("range"),
 		JSONHistoryData:  template.JS(string(jsonHistory)),
-		TotalItems:       int64(len(historyData)),
-		TotalPages:       1,
-		PageNumber:       1,
-		PageSize:         len(historyData),
-		GlobalUptimePct:  uptimePerc,
+		RollupData:       rollupData,
+		JSONRollupData:   template.JS(string(jsonRollups)),
+		ChartResolution:  resolution,
+		LatencyWindow:    latencyWindow,
+		LatencyWindows:   models.LatencyWindows,
+		GlobalLatency:    globalLatency,
+		TargetLatency:    targetLatency,
+
+		SLATarget:           h.App.Store.GetFloatSetting("sla_target", models.DefaultSLATarget),
+		GlobalAvailability:  globalAvailability,
+		TargetAvailability:  targetAvailability,
+		AvailabilityWindows: models.AvailabilityWindows,
+		TotalItems:          int64(len(historyData)),
+		TotalPages:          1,
+		PageNumber:          1,
+		PageSize:            len(historyData),
+		GlobalUptimePct:     uptimePerc,
 	}
 
 	// Render template DASHBOARD
@@ -145,6 +199,7 @@ func (h *Handlers) URLsPage(w http.ResponseWriter, r *http.Request) {
 		Page:            "urls",
 		URLs:            urls,
 		LastCheckedTime: getLatestProbeTime(urls),
+		ProbeTypes:      probe.Types(),
 	}
 
 	// Render template URLS (parse spesifik agar konten sesuai halaman)
@@ -219,9 +274,25 @@ func (h *Handlers) SchedulerPage(w http.ResponseWriter, r *http.Request) {
 		pages = append(pages, i)
 	}
 
+	// Run log scheduler
+	lastRun, err := h.App.Store.GetLastSchedulerRun()
+	if err != nil {
+		log.Printf("Gagal mengambil run terakhir: %v", err)
+	}
+	runs, err := h.App.Store.GetSchedulerRuns(20)
+	if err != nil {
+		log.Printf("Gagal mengambil run log: %v", err)
+	}
+
 	data := models.PageData{
 		Page:            "scheduler",
 		CurrentInterval: interval,
+		LastRun:         lastRun,
+		SchedulerRuns:   runs,
+		Concurrency:     h.App.Store.GetIntSetting("probe_concurrency", scheduler.DefaultConcurrency),
+		PerHostLimit:    h.App.Store.GetIntSetting("probe_per_host", scheduler.DefaultPerHost),
+		RetentionDays:   h.App.Store.GetIntSetting("history_retention_days", scheduler.DefaultRetentionDays),
+		SLATarget:       h.App.Store.GetFloatSetting("sla_target", models.DefaultSLATarget),
 		LastCheckedTime: getLatestProbeTime(urls),
 		HistoryData:     historyData,
 		PageNumber:      pageNum,
@@ -249,19 +320,421 @@ func (h *Handlers) SchedulerPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// IncidentsPage menangani halaman '/incidents', opsional difilter per target
+// lewat query param url_id
+func (h *Handlers) IncidentsPage(w http.ResponseWriter, r *http.Request) {
+	urls, err := h.App.Store.GetAllURLs()
+	if err != nil {
+		log.Printf("Gagal mengambil URL: %v", err)
+		http.Error(w, "Gagal mengambil data", http.StatusInternalServerError)
+		return
+	}
+
+	selectedID, _ := strconv.Atoi(r.URL.Query().Get("url_id"))
+	incidents, err := h.App.Store.GetIncidents(selectedID, 200)
+	if err != nil {
+		log.Printf("Gagal mengambil incident: %v", err)
+	}
+
+	data := models.PageData{
+		Page:            "incidents",
+		URLs:            urls,
+		SelectedURLID:   selectedID,
+		Incidents:       incidents,
+		LastCheckedTime: getLatestProbeTime(urls),
+	}
+
+	tpl, perr := template.ParseFiles("templates/layout.html", "templates/incidents.html")
+	if perr != nil {
+		log.Printf("Error parsing incidents templates: %v", perr)
+		http.Error(w, perr.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = tpl.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		log.Printf("Error rendering incidents template: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// NotificationsPage menangani halaman '/notifications': daftar channel
+// alert dan log pengiriman
+func (h *Handlers) NotificationsPage(w http.ResponseWriter, r *http.Request) {
+	urls, _ := h.App.Store.GetAllURLs()
+
+	channels, err := h.App.Store.GetChannels(false)
+	if err != nil {
+		log.Printf("Gagal mengambil channel notifikasi: %v", err)
+	}
+	deliveries, err := h.App.Store.GetDeliveries(50)
+	if err != nil {
+		log.Printf("Gagal mengambil log pengiriman: %v", err)
+	}
+
+	smtpConfig, err := h.App.Store.GetSMTPConfig()
+	if err != nil {
+		log.Printf("Gagal mengambil pengaturan SMTP: %v", err)
+	}
+	publicURL, _ := h.App.Store.GetSetting("public_url", "")
+
+	data := models.PageData{
+		Page:            "notifications",
+		SMTP:            smtpConfig,
+		PublicURL:       publicURL,
+		Channels:        channels,
+		ChannelTypes:    notify.Types(),
+		Deliveries:      deliveries,
+		LastCheckedTime: getLatestProbeTime(urls),
+	}
+
+	tpl, perr := template.ParseFiles("templates/layout.html", "templates/notifications.html")
+	if perr != nil {
+		log.Printf("Error parsing notifications templates: %v", perr)
+		http.Error(w, perr.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = tpl.ExecuteTemplate(w, "layout", data)
+	if err != nil {
+		log.Printf("Error rendering notifications template: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AddChannel menangani form 'Tambah Channel' pada halaman notifikasi
+func (h *Handlers) AddChannel(w http.ResponseWriter, r *http.Request) {
+	channel := models.NotificationChannel{
+		Name:    strings.TrimSpace(r.FormValue("name")),
+		Type:    r.FormValue("type"),
+		URL:     strings.TrimSpace(r.FormValue("url")),
+		Secret:  r.FormValue("secret"),
+		Enabled: true,
+	}
+	if channel.Type == "" {
+		channel.Type = models.ChannelWebhook
+	}
+	if channel.Name == "" {
+		channel.Name = channel.Type
+	}
+	if err := notify.Validate(channel); err != nil {
+		log.Printf("Channel notifikasi tidak valid: %v", err)
+		http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+		return
+	}
+	if _, err := h.App.Store.AddChannel(channel); err != nil {
+		log.Printf("Gagal menambah channel notifikasi: %v", err)
+	}
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
+// UpdateSMTPSettings menyimpan pengaturan server SMTP untuk channel email.
+// Password yang dikosongkan tidak mengubah password tersimpan.
+func (h *Handlers) UpdateSMTPSettings(w http.ResponseWriter, r *http.Request) {
+	current, err := h.App.Store.GetSMTPConfig()
+	if err != nil {
+		log.Printf("Gagal mengambil pengaturan SMTP: %v", err)
+		http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+		return
+	}
+
+	port, err := formInt(r, "smtp_port", models.DefaultSMTPPort, 1, 65535)
+	if err != nil {
+		log.Printf("Port SMTP tidak valid: %v", err)
+		http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+		return
+	}
+	cfg := models.SMTPConfig{
+		Host:     strings.TrimSpace(r.FormValue("smtp_host")),
+		Port:     port,
+		StartTLS: r.FormValue("smtp_starttls") != "",
+		Username: strings.TrimSpace(r.FormValue("smtp_username")),
+		Password: r.FormValue("smtp_password"),
+		From:     strings.TrimSpace(r.FormValue("smtp_from")),
+		To:       models.SplitAddresses(r.FormValue("smtp_to")),
+	}
+	if cfg.Password == "" {
+		cfg.Password = current.Password
+	}
+	if cfg.Username == "" {
+		cfg.Password = ""
+	}
+
+	if cfg.From != "" {
+		if _, err := mail.ParseAddress(cfg.From); err != nil {
+			log.Printf("Alamat pengirim tidak valid: %v", err)
+			http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+			return
+		}
+	}
+	for _, addr := range cfg.To {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			log.Printf("Alamat penerima tidak valid %q: %v", addr, err)
+			http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+			return
+		}
+	}
+
+	if err := h.App.Store.SaveSMTPConfig(cfg); err != nil {
+		log.Printf("Failed to save SMTP settings: %v", err)
+	}
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
+// UpdateNotificationSettings menyimpan URL publik fprobe yang dipakai untuk
+// link di pesan alert. Kosong berarti alert dikirim tanpa link.
+func (h *Handlers) UpdateNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	publicURL := strings.TrimRight(strings.TrimSpace(r.FormValue("public_url")), "/")
+	if publicURL != "" && !strings.HasPrefix(publicURL, "http://") && !strings.HasPrefix(publicURL, "https://") {
+		log.Printf("Public URL tidak valid: %q", publicURL)
+		http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+		return
+	}
+	if err := h.App.Store.SetSetting("public_url", publicURL); err != nil {
+		log.Printf("Failed to save public_url setting: %v", err)
+	}
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
+// DeleteChannel menghapus channel notifikasi
+func (h *Handlers) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+		return
+	}
+	if err := h.App.Store.DeleteChannel(id); err != nil {
+		log.Printf("Gagal menghapus channel notifikasi: %v", err)
+	}
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
+// TestChannel mengirim alert percobaan ke satu channel di background agar
+// request tidak tertahan oleh retry. Hasilnya tampil di log pengiriman.
+func (h *Handlers) TestChannel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+		return
+	}
+	channel, err := h.App.Store.GetChannel(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("Gagal mengambil channel notifikasi: %v", err)
+		http.Error(w, "Gagal mengambil data", http.StatusInternalServerError)
+		return
+	}
+
+	go h.App.Notifier.Deliver(channel, models.AlertEvent{
+		URL:       "https://example.com",
+		ProbeType: models.ProbeHTTP,
+		OldState:  models.StateUp,
+		NewState:  models.StateDown,
+		Error:     "Alert percobaan dari fprobe",
+		Timestamp: time.Now(),
+		Test:      true,
+	})
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
 // AddURL menangani form 'Tambah URL'
 func (h *Handlers) AddURL(w http.ResponseWriter, r *http.Request) {
-	url := r.FormValue("url")
+	url := strings.TrimSpace(r.FormValue("url"))
 	if url == "" {
 		http.Redirect(w, r, "/urls", http.StatusSeeOther)
 		return
 	}
-	if !((strings.HasPrefix(url, "http://")) || (strings.HasPrefix(url, "https://"))) {
-		url = "https://" + url
+
+	probeType := r.FormValue("probe_type")
+	if probeType == "" {
+		probeType = models.ProbeHTTP
+	}
+	if probeType == models.ProbeHTTP {
+		if !((strings.HasPrefix(url, "http://")) || (strings.HasPrefix(url, "https://"))) {
+			url = "https://" + url
+		}
 	}
-	err := h.App.Store.AddURL(url)
+
+	method := strings.ToUpper(strings.TrimSpace(r.FormValue("method")))
+	if method == "" {
+		method = http.MethodGet
+	}
+	headers, err := models.ParseHeaderLines(r.FormValue("headers"))
+	if err != nil {
+		log.Printf("Gagal membaca header: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	expectedStatus := strings.TrimSpace(r.FormValue("expected_status"))
+	if expectedStatus == "" {
+		expectedStatus = models.DefaultExpectedStatus
+	}
+	if err := models.ValidateStatusRules(expectedStatus); err != nil {
+		log.Printf("Aturan status tidak valid: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	assertions, err := models.ParseAssertions(r.FormValue("assertions"))
+	if err != nil {
+		log.Printf("Assertion tidak valid: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	certWarnDays := models.DefaultCertWarnDays
+	if v := r.FormValue("cert_warn_days"); v != "" {
+		n, convErr := strconv.Atoi(v)
+		if convErr != nil || n <= 0 {
+			log.Printf("Cert warn days tidak valid: %q", v)
+			http.Redirect(w, r, "/urls", http.StatusSeeOther)
+			return
+		}
+		certWarnDays = n
+	}
+
+	retentionDays, err := formInt(r, "retention_days", 0, 0, scheduler.MaxRetentionDays)
+	if err != nil {
+		log.Printf("Retensi tidak valid: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	// Target SLA khusus (0 = ikut setting global)
+	slaTarget, err := formSLA(r, "sla_target", 0, true)
+	if err != nil {
+		log.Printf("Target SLA tidak valid: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	// Retry dan ambang konfirmasi down
+	retryCount, err := formInt(r, "retry_count", 0, 0, models.MaxRetryCount)
+	var retryDelayMs, failureThreshold int
+	if err == nil {
+		retryDelayMs, err = formInt(r, "retry_delay_ms", models.DefaultRetryDelayMs, 0, models.MaxRetryDelayMs)
+	}
+	if err == nil {
+		failureThreshold, err = formInt(r, "failure_threshold", models.DefaultFailureThreshold, 1, models.MaxFailureThreshold)
+	}
+	if err != nil {
+		log.Printf("Pengaturan retry tidak valid: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	// Ambang latency warning/critical (0 = nonaktif)
+	latencyWarnMs, err := formInt(r, "latency_warn_ms", 0, 0, models.MaxLatencyThresholdMs)
+	var latencyCritMs, latencyAvgProbes int
+	if err == nil {
+		latencyCritMs, err = formInt(r, "latency_crit_ms", 0, 0, models.MaxLatencyThresholdMs)
+	}
+	if err == nil {
+		latencyAvgProbes, err = formInt(r, "latency_avg_probes", 1, 1, models.MaxLatencyAvgProbes)
+	}
+	if err == nil {
+		err = models.ValidateLatencyThresholds(latencyWarnMs, latencyCritMs)
+	}
+	if err != nil {
+		log.Printf("Ambang latency tidak valid: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	checkInterval := strings.TrimSpace(r.FormValue("check_interval"))
+	if checkInterval != "" {
+		if err := scheduler.ValidateInterval(checkInterval); err != nil {
+			log.Printf("Interval target tidak valid: %v", err)
+			http.Redirect(w, r, "/urls", http.StatusSeeOther)
+			return
+		}
+	}
+
+	dnsRecordType := strings.ToUpper(strings.TrimSpace(r.FormValue("dns_record_type")))
+	if dnsRecordType == "" {
+		dnsRecordType = models.DNSRecordA
+	}
+
+	target := models.TargetURL{
+		URL:            url,
+		ProbeType:      probeType,
+		Method:         method,
+		Headers:        headers,
+		Body:           r.FormValue("body"),
+		ExpectedStatus: expectedStatus,
+		Assertions:     assertions,
+		CertWarnDays:   certWarnDays,
+		TCPSend:        r.FormValue("tcp_send"),
+		TCPExpect:      r.FormValue("tcp_expect"),
+		DNSRecordType:  dnsRecordType,
+		DNSResolver:    strings.TrimSpace(r.FormValue("dns_resolver")),
+		DNSExpected:    strings.TrimSpace(r.FormValue("dns_expected")),
+		CheckInterval:  checkInterval,
+
+		RetryCount:       retryCount,
+		RetryDelayMs:     retryDelayMs,
+		FailureThreshold: failureThreshold,
+		RetentionDays:    retentionDays,
+		SLATarget:        slaTarget,
+		LatencyWarnMs:    latencyWarnMs,
+		LatencyCritMs:    latencyCritMs,
+		LatencyAvgProbes: latencyAvgProbes,
+	}
+	// Validasi pengaturan lewat prober sesuai jenis probe
+	if _, err := probe.New(target); err != nil {
+		log.Printf("Pengaturan target tidak valid: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	target.ID, err = h.App.Store.AddURL(target)
 	if err != nil {
 		log.Printf("Gagal menambah URL: %v", err)
+	} else if err := h.App.Scheduler.Reschedule(target); err != nil {
+		log.Printf("Gagal menjadwalkan URL: %v", err)
+	}
+	http.Redirect(w, r, "/urls", http.StatusSeeOther)
+}
+
+// UpdateURLInterval menangani form interval pada tabel '/urls'. Hanya entry
+// cron milik target tersebut yang dijadwalkan ulang.
+func (h *Handlers) UpdateURLInterval(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	interval := strings.TrimSpace(r.FormValue("check_interval"))
+	if interval != "" {
+		if err := scheduler.ValidateInterval(interval); err != nil {
+			log.Printf("Interval target tidak valid: %v", err)
+			http.Redirect(w, r, "/urls", http.StatusSeeOther)
+			return
+		}
+	}
+
+	target, err := h.App.Store.GetURL(id)
+	if err != nil {
+		log.Printf("Gagal mengambil URL %d: %v", id, err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+	if target.CheckInterval == interval {
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+	if err := h.App.Store.SetCheckInterval(id, interval); err != nil {
+		log.Printf("Gagal menyimpan interval URL: %v", err)
+		http.Redirect(w, r, "/urls", http.StatusSeeOther)
+		return
+	}
+
+	target.CheckInterval = interval
+	if err := h.App.Scheduler.Reschedule(target); err != nil {
+		log.Printf("Gagal menjadwalkan ulang URL: %v", err)
 	}
 	http.Redirect(w, r, "/urls", http.StatusSeeOther)
 }
@@ -284,9 +757,106 @@ func (h *Handlers) DeleteURL(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Gagal menghapus URL: %v", err)
 	}
+	h.App.Scheduler.Remove(id)
 	http.Redirect(w, r, "/urls", http.StatusSeeOther)
 }
 
+// probeResponse adalah hasil probe satu target untuk endpoint "Probe Now"
+type probeResponse struct {
+	ID           int       `json:"id"`
+	URL          string    `json:"url"`
+	Up           bool      `json:"up"`
+	ProbeUp      bool      `json:"probe_up"`
+	Attempts     int       `json:"attempts"`
+	Degraded     bool      `json:"degraded"`
+	LatencyLevel string    `json:"latency_level,omitempty"`
+	StatusCode   int       `json:"status_code"`
+	LatencyMs    int64     `json:"latency_ms"`
+	Error        string    `json:"error,omitempty"`
+	ErrorClass   string    `json:"error_class,omitempty"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+func newProbeResponse(u models.TargetURL) probeResponse {
+	resp := probeResponse{
+		ID:           u.ID,
+		URL:          u.URL,
+		Up:           u.IsUp,
+		ProbeUp:      u.LastProbeUp,
+		Attempts:     u.LastAttempts,
+		Degraded:     u.IsDegraded,
+		LatencyLevel: u.LatencyLevel,
+		StatusCode:   u.LastStatus,
+		LatencyMs:    u.LastLatencyMs,
+		CheckedAt:    u.LastChecked,
+	}
+	if !u.IsUp {
+		resp.Error = u.GetDownReason()
+	} else {
+		resp.Error = u.GetPendingFailure()
+	}
+	if !u.LastProbeUp {
+		resp.ErrorClass = string(u.LastErrorClass)
+	}
+	return resp
+}
+
+// writeJSON menulis response JSON dengan status code tertentu
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Gagal menulis response JSON: %v", err)
+	}
+}
+
+// ProbeNow menangani 'POST /urls/{id}/probe': probe satu target saat itu
+// juga dan mengembalikan hasilnya sebagai JSON
+func (h *Handlers) ProbeNow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "ID tidak valid"})
+		return
+	}
+
+	target, err := h.App.Scheduler.ProbeNow(id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "URL tidak ditemukan"})
+		return
+	case errors.Is(err, scheduler.ErrBusy):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Gagal probe URL %d: %v", id, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Gagal menjalankan probe"})
+		return
+	}
+	writeJSON(w, http.StatusOK, newProbeResponse(target))
+}
+
+// ProbeAllNow menangani 'POST /probe': probe semua target saat itu juga dan
+// mengembalikan ringkasan serta hasil setiap target sebagai JSON
+func (h *Handlers) ProbeAllNow(w http.ResponseWriter, r *http.Request) {
+	run, urls, err := h.App.Scheduler.ProbeAllNow()
+	if err != nil {
+		log.Printf("Gagal probe semua URL: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Gagal menjalankan probe"})
+		return
+	}
+
+	results := make([]probeResponse, 0, len(urls))
+	for _, u := range urls {
+		results = append(results, newProbeResponse(u))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"targets":     run.Targets,
+		"failures":    run.Failures,
+		"duration_ms": run.FinishedAt.Sub(run.StartedAt).Milliseconds(),
+		"results":     results,
+	})
+}
+
 // UpdateSettings menangani form 'Simpan Jadwal'
 func (h *Handlers) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 	interval := r.FormValue("interval")
@@ -303,6 +873,55 @@ func (h *Handlers) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/scheduler", http.StatusSeeOther)
 		return
 	}
+
+	// Ukuran worker pool (opsional di form)
+	for _, key := range []string{"probe_concurrency", "probe_per_host"} {
+		v := r.FormValue(key)
+		if v == "" {
+			continue
+		}
+		n, convErr := strconv.Atoi(v)
+		if convErr != nil || n <= 0 || n > 500 {
+			log.Printf("Nilai %s tidak valid: %q", key, v)
+			http.Redirect(w, r, "/scheduler", http.StatusSeeOther)
+			return
+		}
+		if err := h.App.Store.SetSetting(key, strconv.Itoa(n)); err != nil {
+			log.Printf("Failed to save %s: %v", key, err)
+		}
+	}
+
+	// Retensi history global (opsional di form)
+	if v := r.FormValue("history_retention_days"); v != "" {
+		days, err := formInt(r, "history_retention_days", scheduler.DefaultRetentionDays, 1, scheduler.MaxRetentionDays)
+		if err != nil {
+			log.Println(err)
+			http.Redirect(w, r, "/scheduler", http.StatusSeeOther)
+			return
+		}
+		if err := h.App.Store.SetSetting("history_retention_days", strconv.Itoa(days)); err != nil {
+			log.Printf("Failed to save history_retention_days: %v", err)
+		}
+	}
+
+	// Target SLA global (opsional di form)
+	if v := r.FormValue("sla_target"); v != "" {
+		target, err := formSLA(r, "sla_target", models.DefaultSLATarget, false)
+		if err != nil || target == 0 {
+			log.Printf("Target SLA tidak valid: %q", v)
+			http.Redirect(w, r, "/scheduler", http.StatusSeeOther)
+			return
+		}
+		if err := h.App.Store.SetSetting("sla_target", strconv.FormatFloat(target, 'f', -1, 64)); err != nil {
+			log.Printf("Failed to save sla_target: %v", err)
+		}
+	}
+
+	currentInterval, _ := h.App.Store.GetScheduleInterval()
+	if interval == currentInterval {
+		http.Redirect(w, r, "/scheduler", http.StatusSeeOther)
+		return
+	}
 	err := h.App.Store.SetScheduleInterval(interval)
 	if err != nil {
 		log.Println("Failed to save interval:", err)
@@ -310,20 +929,49 @@ func (h *Handlers) UpdateSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Restart Cron Job
+	// Jadwalkan ulang job global saja; target dengan interval sendiri tetap jalan
 	log.Printf("Changing scheduler interval to: %s", interval)
-	h.App.Scheduler.Remove(h.App.JobID)
-	newID, err := h.App.Scheduler.AddFunc(interval, scheduler.CreateJob(h.App.Store))
-	if err != nil {
-		log.Println("Failed to add new cron job:", err)
+	if err := h.App.Scheduler.SetGlobalInterval(interval); err != nil {
+		log.Println("Failed to reschedule global job:", err)
 	}
-	h.App.JobID = newID
 
 	http.Redirect(w, r, "/scheduler", http.StatusSeeOther)
 }
 
 // === FUNCTION HELPER ===
 
+// formInt membaca field angka dari form. Field kosong menghasilkan def,
+// nilai di luar rentang lo..hi menghasilkan error.
+func formInt(r *http.Request, key string, def, lo, hi int) (int, error) {
+	v := strings.TrimSpace(r.FormValue(key))
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("nilai %s tidak valid: %q (harus %d-%d)", key, v, lo, hi)
+	}
+	return n, nil
+}
+
+// formSLA membaca target SLA (persen, 0 < x <= 100) dari form. Field kosong
+// menghasilkan def. Jika inherit, nilai 0 juga diterima dan berarti ikut
+// target SLA global.
+func formSLA(r *http.Request, key string, def float64, inherit bool) (float64, error) {
+	v := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(r.FormValue(key)), "%"))
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err == nil && f == 0 && inherit {
+		return 0, nil
+	}
+	if err != nil || f <= 0 || f > 100 {
+		return 0, fmt.Errorf("nilai %s tidak valid: %q (harus 0-100)", key, v)
+	}
+	return f, nil
+}
+
 // calculateGlobalAvgLatency menghitung rata-rata dari semua URL
 func calculateGlobalAvgLatency(urls []models.TargetURL) int64 {
 	var totalSum, totalCount int64
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFormSLA(t *testing.T) {
+	tests := []struct {
+		value   string
+		inherit bool
+		want    float64
+		wantErr bool
+	}{
+		{"", true, 99.9, false},
+		{"0", true, 0, false},
+		{"0%", true, 0, false},
+		{"99.5", true, 99.5, false},
+		{"99.9 %", false, 99.9, false},
+		{"", false, 99.9, false},
+		{"0", false, 0, true},
+		{"-1", true, 0, true},
+		{"100.1", true, 0, true},
+		{"abc", true, 0, true},
+	}
+	for _, tt := range tests {
+		form := url.Values{"sla_target": {tt.value}}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		got, err := formSLA(r, "sla_target", 99.9, tt.inherit)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("formSLA(%q, inherit=%v) = %v, %v; want %v, error %v", tt.value, tt.inherit, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"test/database"
 	"test/handler"
+	"test/notify"
 	"test/scheduler"
 
 	"github.com/gorilla/mux"
@@ -42,10 +43,14 @@ func main() {
 	app := &handler.Application{
 		Store:     store,
 		Templates: tpl,
+		Notifier:  notify.New(store),
 	}
 
 	// Mulai Scheduler dan simpan state-nya ke 'app'
-	app.Scheduler, app.JobID = scheduler.StartScheduler(initialInterval, app.Store)
+	app.Scheduler, err = scheduler.Start(initialInterval, app.Store, app.Notifier)
+	if err != nil {
+		log.Fatalf("Gagal memulai scheduler: %v", err)
+	}
 
 	// Setup Handlers
 	h := handler.NewHandlers(app)
@@ -55,11 +60,21 @@ func main() {
 	r.HandleFunc("/", h.DashboardPage).Methods("GET")
 	r.HandleFunc("/urls", h.URLsPage).Methods("GET")
 	r.HandleFunc("/scheduler", h.SchedulerPage).Methods("GET")
+	r.HandleFunc("/incidents", h.IncidentsPage).Methods("GET")
+	r.HandleFunc("/notifications", h.NotificationsPage).Methods("GET")
 
 	// Routing untuk Aksi (POST/GET)
 	r.HandleFunc("/add", h.AddURL).Methods("POST")
 	r.HandleFunc("/delete/{id:[0-9]+}", h.DeleteURL).Methods("GET")
+	r.HandleFunc("/urls/{id:[0-9]+}/interval", h.UpdateURLInterval).Methods("POST")
+	r.HandleFunc("/urls/{id:[0-9]+}/probe", h.ProbeNow).Methods("POST")
+	r.HandleFunc("/probe", h.ProbeAllNow).Methods("POST")
 	r.HandleFunc("/settings", h.UpdateSettings).Methods("POST")
+	r.HandleFunc("/notifications/add", h.AddChannel).Methods("POST")
+	r.HandleFunc("/notifications/smtp", h.UpdateSMTPSettings).Methods("POST")
+	r.HandleFunc("/notifications/settings", h.UpdateNotificationSettings).Methods("POST")
+	r.HandleFunc("/notifications/delete/{id:[0-9]+}", h.DeleteChannel).Methods("GET")
+	r.HandleFunc("/notifications/{id:[0-9]+}/test", h.TestChannel).Methods("POST")
 
 	// Routing untuk file statis (CSS, JS, Gambar)
 	fs := http.FileServer(http.Dir("./static/"))
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Jenis assertion terhadap body response
+const (
+	AssertContains    = "contains"
+	AssertNotContains = "not_contains"
+	AssertRegex       = "regex"
+	AssertJSON        = "json"
+)
+
+// Assertion adalah satu pemeriksaan isi response yang harus lolos agar
+// target dianggap up.
+type Assertion struct {
+	Type  string
+	Value string
+}
+
+// AssertionResult adalah hasil evaluasi satu assertion pada probe terakhir
+type AssertionResult struct {
+	Assertion string
+	Passed    bool
+	Message   string
+}
+
+// String mengembalikan assertion dalam format yang sama dengan input form
+func (a Assertion) String() string {
+	return fmt.Sprintf("%s: %s", a.Type, a.Value)
+}
+
+// ParseAssertions membaca assertion dari teks, satu per baris dengan format
+// "jenis: nilai", contoh:
+//
+//	contains: "status":"ok"
+//	not_contains: Internal Server Error
+//	regex: version \d+\.\d+
+//	json: $.db == "up"
+//
+// Validasi ekspresi JSON dilakukan saat prober HTTP dikonfigurasi (probe.New).
+func ParseAssertions(text string) ([]Assertion, error) {
+	var assertions []Assertion
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		kind, value, ok := strings.Cut(line, ":")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("assertion tidak valid: %q (format: jenis: nilai)", line)
+		}
+
+		switch kind {
+		case AssertContains, AssertNotContains, AssertJSON:
+		case AssertRegex:
+			if _, err := regexp.Compile(value); err != nil {
+				return nil, fmt.Errorf("regex tidak valid pada %q: %w", line, err)
+			}
+		default:
+			return nil, fmt.Errorf("jenis assertion tidak dikenal: %q", kind)
+		}
+		assertions = append(assertions, Assertion{Type: kind, Value: value})
+	}
+	return assertions, nil
+}
+
+// GetAssertionLines mengembalikan assertion target dalam format input form
+func (tu *TargetURL) GetAssertionLines() string {
+	lines := make([]string, 0, len(tu.Assertions))
+	for _, a := range tu.Assertions {
+		lines = append(lines, a.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,72 @@
+package models
+
+import "fmt"
+
+// DefaultSLATarget adalah target availability (persen) bawaan, dipakai jika
+// setting sla_target belum diatur
+const DefaultSLATarget = 99.9
+
+// SLAWindow adalah jendela yang menentukan apakah target melanggar SLA
+const SLAWindow = Window30d
+
+// AvailabilityWindows adalah jendela availability yang ditampilkan, urut dari
+// yang terpendek
+var AvailabilityWindows = []string{Window24h, Window7d, Window30d, Window90d}
+
+// Availability adalah jumlah probe dan probe gagal satu target (atau semua
+// target) dalam satu jendela waktu
+type Availability struct {
+	Window   string
+	Total    int64
+	Failures int64
+}
+
+// HasData bernilai true jika ada probe dalam jendela ini
+func (a Availability) HasData() bool {
+	return a.Total > 0
+}
+
+// Pct mengembalikan persentase probe yang berhasil (0 jika belum ada data)
+func (a Availability) Pct() float64 {
+	if a.Total == 0 {
+		return 0
+	}
+	return 100 * float64(a.Total-a.Failures) / float64(a.Total)
+}
+
+// FormatPct menampilkan persentase dengan tiga desimal agar target seperti
+// 99.95% tetap terbaca, atau "N/A" jika belum ada data
+func (a Availability) FormatPct() string {
+	if a.Total == 0 {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.3f%%", a.Pct())
+}
+
+// Breaches bernilai true jika availability berada di bawah target SLA
+func (a Availability) Breaches(target float64) bool {
+	return a.Total > 0 && a.Pct() < target
+}
+
+// AvailabilitySet adalah availability satu target untuk setiap jendela di
+// AvailabilityWindows (urutan sama)
+type AvailabilitySet []Availability
+
+// SLA mengembalikan availability pada jendela SLAWindow
+func (set AvailabilitySet) SLA() Availability {
+	for _, a := range set {
+		if a.Window == SLAWindow {
+			return a
+		}
+	}
+	return Availability{Window: SLAWindow}
+}
+
+// GetSLATarget mengembalikan target SLA milik target, atau global jika
+// target tidak punya target sendiri
+func (tu *TargetURL) GetSLATarget(global float64) float64 {
+	if tu.SLATarget > 0 {
+		return tu.SLATarget
+	}
+	return global
+}
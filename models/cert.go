@@ -0,0 +1,78 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DefaultCertWarnDays adalah batas default (hari) sebelum sertifikat
+// kadaluarsa di mana target dianggap degraded.
+const DefaultCertWarnDays = 14
+
+// CertInfo adalah ringkasan sertifikat leaf dari TLS handshake
+type CertInfo struct {
+	Expiry time.Time
+	Issuer string
+	SANs   []string
+}
+
+// HasCert bernilai true jika target pernah mengirim sertifikat TLS
+func (tu *TargetURL) HasCert() bool {
+	return tu.CertExpiry.Valid
+}
+
+// CertDaysRemaining menghitung sisa hari penuh sebelum sertifikat
+// kadaluarsa (negatif jika sudah lewat).
+func (tu *TargetURL) CertDaysRemaining() int {
+	return tu.certDaysRemainingAt(time.Now())
+}
+
+// certDaysRemainingAt menghitung sisa hari relatif terhadap now. Dibulatkan
+// ke bawah, sehingga sertifikat yang baru lewat beberapa jam bernilai -1,
+// bukan 0.
+func (tu *TargetURL) certDaysRemainingAt(now time.Time) int {
+	if !tu.CertExpiry.Valid {
+		return 0
+	}
+	return int(math.Floor(tu.CertExpiry.Time.Sub(now).Hours() / 24))
+}
+
+// CertExpiringSoon bernilai true jika sisa masa berlaku sertifikat di bawah
+// ambang CertWarnDays target.
+func (tu *TargetURL) CertExpiringSoon() bool {
+	if !tu.CertExpiry.Valid {
+		return false
+	}
+	return tu.CertDaysRemaining() < tu.CertWarnDays
+}
+
+// GetCertSummary mengembalikan issuer dan SAN untuk tooltip di tabel URL
+func (tu *TargetURL) GetCertSummary() string {
+	if !tu.CertExpiry.Valid {
+		return ""
+	}
+	return fmt.Sprintf("Issuer: %s\nExpires: %s\nSAN: %s",
+		tu.CertIssuer, tu.CertExpiry.Time.Format("2 Jan 2006 15:04"), strings.Join(tu.CertSANs, ", "))
+}
+
+// GetDegradedReason menjelaskan kenapa target degraded ("" jika tidak)
+func (tu *TargetURL) GetDegradedReason() string {
+	if !tu.IsDegraded {
+		return ""
+	}
+	var reasons []string
+	if tu.IsLatencyDegraded() {
+		reasons = append(reasons, tu.LatencyReason(tu.LatencyLevel, tu.LatencyCheckMs))
+	}
+	if tu.CertExpiringSoon() {
+		days := tu.CertDaysRemaining()
+		if days < 0 {
+			reasons = append(reasons, "Sertifikat TLS sudah kadaluarsa")
+		} else {
+			reasons = append(reasons, fmt.Sprintf("Sertifikat TLS kadaluarsa dalam %d hari", days))
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestCertDaysRemaining(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		expiry time.Duration
+		want   int
+	}{
+		{"sisa lebih dari sehari", 36 * time.Hour, 1},
+		{"tepat satu hari", 24 * time.Hour, 1},
+		{"kurang dari sehari", 23 * time.Hour, 0},
+		{"kadaluarsa sekarang", 0, 0},
+		{"baru lewat satu jam", -time.Hour, -1},
+		{"lewat tepat satu hari", -24 * time.Hour, -1},
+		{"lewat lebih dari sehari", -25 * time.Hour, -2},
+	}
+	for _, tt := range tests {
+		target := TargetURL{CertExpiry: sql.NullTime{Time: now.Add(tt.expiry), Valid: true}}
+		if got := target.certDaysRemainingAt(now); got != tt.want {
+			t.Errorf("%s: certDaysRemainingAt = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCertJustExpiredIsReportedExpired(t *testing.T) {
+	target := TargetURL{
+		IsDegraded:   true,
+		CertWarnDays: DefaultCertWarnDays,
+		CertExpiry:   sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+	if got := target.GetDegradedReason(); got != "Sertifikat TLS sudah kadaluarsa" {
+		t.Errorf("GetDegradedReason() = %q, want expired", got)
+	}
+}
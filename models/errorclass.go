@@ -0,0 +1,57 @@
+package models
+
+// ErrorClass adalah kode jenis kegagalan probe, disimpan di
+// probe_history.error_class dan urls.last_error_class
+type ErrorClass string
+
+// Kelas kegagalan umum
+const (
+	ErrorClassNetwork   ErrorClass = "network"   // tidak ada response, penyebab tidak dikenali
+	ErrorClassStatus    ErrorClass = "status"    // status code tidak sesuai ExpectedStatus
+	ErrorClassAssertion ErrorClass = "assertion" // assertion body / banner / record gagal
+	ErrorClassConfig    ErrorClass = "config"    // konfigurasi target tidak valid
+)
+
+// Kelas kegagalan jaringan yang dikenali oleh prober
+const (
+	ErrorDNSNXDomain      ErrorClass = "dns_nxdomain"       // domain tidak ditemukan
+	ErrorDNSNoRecords     ErrorClass = "dns_no_records"     // domain ada tapi tanpa record jenis yang diminta (NODATA)
+	ErrorDNS              ErrorClass = "dns_error"          // resolusi DNS gagal (selain NXDOMAIN)
+	ErrorConnRefused      ErrorClass = "conn_refused"       // port tertutup / service mati
+	ErrorConnReset        ErrorClass = "conn_reset"         // koneksi diputus oleh peer
+	ErrorTimeout          ErrorClass = "timeout"            // melewati batas waktu probe
+	ErrorTLSHandshake     ErrorClass = "tls_handshake"      // negosiasi TLS gagal
+	ErrorCertInvalid      ErrorClass = "cert_invalid"       // sertifikat tidak dipercaya / kadaluarsa / salah host
+	ErrorTooManyRedirects ErrorClass = "too_many_redirects" // redirect melebihi batas
+)
+
+// Hint mengembalikan petunjuk singkat di mana mencari penyebab kegagalan
+func (c ErrorClass) Hint() string {
+	switch c {
+	case ErrorDNSNXDomain:
+		return "Periksa nama domain dan record DNS"
+	case ErrorDNSNoRecords:
+		return "Domain ada tapi tidak punya record jenis ini"
+	case ErrorDNS:
+		return "Periksa resolver DNS"
+	case ErrorConnRefused:
+		return "Service tidak listen di port tersebut"
+	case ErrorConnReset:
+		return "Koneksi diputus oleh server, load balancer atau firewall"
+	case ErrorTimeout:
+		return "Server tidak merespons dalam batas waktu"
+	case ErrorTLSHandshake:
+		return "Periksa konfigurasi TLS di server"
+	case ErrorCertInvalid:
+		return "Periksa sertifikat (issuer, masa berlaku, nama host)"
+	case ErrorTooManyRedirects:
+		return "Periksa loop redirect"
+	case ErrorClassStatus:
+		return "Server merespons dengan status yang tidak diharapkan"
+	case ErrorClassAssertion:
+		return "Response tidak sesuai assertion"
+	case ErrorClassConfig:
+		return "Periksa konfigurasi target"
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Incident adalah satu periode down sebuah target (tabel incidents). Dibuka
+// saat target berubah dari up ke down dan ditutup saat target pulih.
+type Incident struct {
+	ID        int64
+	URLID     int
+	URL       string
+	StartedAt time.Time
+	// EndedAt kosong selama incident masih berlangsung
+	EndedAt sql.NullTime
+
+	// Kegagalan pertama yang membuka incident
+	StatusCode   int
+	ErrorClass   ErrorClass
+	ErrorMessage string
+
+	// ProbeCount adalah jumlah run gagal selama incident
+	ProbeCount int
+}
+
+// IsOpen bernilai true jika target masih down
+func (i Incident) IsOpen() bool {
+	return !i.EndedAt.Valid
+}
+
+// Duration adalah lama incident, atau lama sampai sekarang jika masih
+// berlangsung
+func (i Incident) Duration() time.Duration {
+	end := time.Now()
+	if i.EndedAt.Valid {
+		end = i.EndedAt.Time
+	}
+	return end.Sub(i.StartedAt)
+}
+
+// GetDuration menampilkan lama incident dalam detik
+func (i Incident) GetDuration() string {
+	return i.Duration().Round(time.Second).String()
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Jendela waktu untuk statistik di dashboard (persentil latency dan
+// availability)
+const (
+	Window1h  = "1h"
+	Window24h = "24h"
+	Window7d  = "7d"
+	Window30d = "30d"
+	Window90d = "90d"
+
+	DefaultLatencyWindow = Window24h
+)
+
+// LatencyWindows adalah pilihan jendela persentil latency, urut dari yang
+// terpendek
+var LatencyWindows = []string{Window1h, Window24h, Window7d, Window30d}
+
+var windowDurations = map[string]time.Duration{
+	Window1h:  time.Hour,
+	Window24h: 24 * time.Hour,
+	Window7d:  7 * 24 * time.Hour,
+	Window30d: 30 * 24 * time.Hour,
+	Window90d: 90 * 24 * time.Hour,
+}
+
+// WindowDuration mengembalikan panjang jendela waktu. ok bernilai false jika
+// jendela tidak dikenal.
+func WindowDuration(window string) (d time.Duration, ok bool) {
+	d, ok = windowDurations[window]
+	return d, ok
+}
+
+// LatencyPercentiles adalah persentil latency (milidetik) dari probe yang up
+// dalam satu jendela waktu. Count 0 berarti tidak ada data.
+type LatencyPercentiles struct {
+	Count int
+	P50   int64
+	P90   int64
+	P95   int64
+	P99   int64
+	// Approximate bernilai true jika dihitung dari rollup per jam (jendela
+	// panjang), bukan dari setiap probe
+	Approximate bool
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status target pada alert
+const (
+	StateUp       = "up"
+	StateDown     = "down"
+	StateDegraded = "degraded"
+)
+
+// Jenis channel notifikasi bawaan
+const (
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelSlack   = "slack"
+	ChannelDiscord = "discord"
+)
+
+// DefaultSMTPPort adalah port submission SMTP (STARTTLS)
+const DefaultSMTPPort = 587
+
+// NotificationChannel adalah satu tujuan alert (tabel notification_channels)
+type NotificationChannel struct {
+	ID   int
+	Name string
+	Type string
+	// URL tujuan (webhook), atau daftar email penerima dipisah koma untuk
+	// channel email (kosong = penerima dari setting smtp_to)
+	URL string
+	// Secret untuk tanda tangan HMAC payload (kosong = tidak ditandatangani)
+	Secret    string
+	Enabled   bool
+	CreatedAt time.Time
+}
+
+// AlertEvent adalah perubahan status satu target yang dikirim ke channel
+// notifikasi
+type AlertEvent struct {
+	URLID      int
+	URL        string
+	ProbeType  string
+	OldState   string
+	NewState   string
+	StatusCode int
+	ErrorClass ErrorClass
+	Error      string
+	LatencyMs  int64
+	// Level adalah tingkat latency (LatencyWarning/LatencyCritical) untuk
+	// alert degraded
+	Level     string
+	Timestamp time.Time
+	// Link ke halaman target di fprobe (kosong jika setting public_url
+	// belum diatur)
+	Link string
+	// Test: alert percobaan dari halaman Notifications
+	Test bool
+}
+
+// Name mengembalikan nama event, mis. "target.down" atau "test"
+func (e AlertEvent) Name() string {
+	if e.Test {
+		return "test"
+	}
+	return "target." + e.NewState
+}
+
+// Title mengembalikan label singkat alert: "DOWN", "DEGRADED", "RECOVERED"
+// atau "TEST"
+func (e AlertEvent) Title() string {
+	switch {
+	case e.Test:
+		return "TEST"
+	case e.NewState == StateDown:
+		return "DOWN"
+	case e.NewState == StateDegraded:
+		return "DEGRADED"
+	default:
+		return "RECOVERED"
+	}
+}
+
+// NotificationDelivery adalah catatan pengiriman satu alert ke satu channel
+// (tabel notification_deliveries)
+type NotificationDelivery struct {
+	ID          int64
+	ChannelID   int
+	ChannelName string
+	URLID       int
+	URL         string
+	Event       string
+	Success     bool
+	StatusCode  int
+	Attempts    int
+	Error       string
+	CreatedAt   time.Time
+}
+
+// SMTPConfig adalah pengaturan server SMTP untuk channel email (disimpan di
+// tabel settings dengan prefix smtp_)
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	StartTLS bool
+	Username string
+	Password string
+	From     string
+	// To adalah penerima bawaan untuk channel email tanpa daftar penerima
+	To []string
+}
+
+// Configured bernilai true jika server dan pengirim sudah diisi
+func (c SMTPConfig) Configured() bool {
+	return c.Host != "" && c.From != ""
+}
+
+// Addr mengembalikan alamat server dalam format host:port
+func (c SMTPConfig) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// GetTo mengembalikan daftar penerima bawaan dalam format input form
+func (c SMTPConfig) GetTo() string {
+	return strings.Join(c.To, ", ")
+}
+
+// SplitAddresses memecah daftar email yang dipisah koma, titik koma atau
+// baris baru
+func SplitAddresses(list string) []string {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n' || r == '\r'
+	})
+	var addresses []string
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			addresses = append(addresses, f)
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,31 @@
+package models
+
+// Jenis probe yang didukung
+const (
+	ProbeHTTP = "http"
+	ProbeTCP  = "tcp"
+	ProbeDNS  = "dns"
+)
+
+// Jenis record yang didukung oleh probe DNS
+const (
+	DNSRecordA     = "A"
+	DNSRecordAAAA  = "AAAA"
+	DNSRecordCNAME = "CNAME"
+	DNSRecordMX    = "MX"
+	DNSRecordTXT   = "TXT"
+)
+
+// ValidDNSRecordTypes dipakai untuk validasi input form
+var ValidDNSRecordTypes = map[string]bool{
+	DNSRecordA:     true,
+	DNSRecordAAAA:  true,
+	DNSRecordCNAME: true,
+	DNSRecordMX:    true,
+	DNSRecordTXT:   true,
+}
+
+// IsHTTP bernilai true untuk target yang diprobe lewat HTTP(S)
+func (tu *TargetURL) IsHTTP() bool {
+	return tu.ProbeType == "" || tu.ProbeType == ProbeHTTP
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Default konfirmasi kegagalan untuk target baru
+const (
+	DefaultRetryDelayMs     = 1000
+	DefaultFailureThreshold = 1
+	// Batas input form
+	MaxRetryCount       = 10
+	MaxRetryDelayMs     = 60000
+	MaxFailureThreshold = 100
+)
+
+// CheckState adalah status target setelah satu run: status terkonfirmasi,
+// awal uptime, jumlah run gagal berturut-turut dan tingkat latency
+type CheckState struct {
+	IsUp                bool
+	FirstUpTime         sql.NullTime
+	ConsecutiveFailures int
+	LatencyLevel        string
+	LatencyCheckMs      int64
+}
+
+// GetPendingFailure menjelaskan kegagalan yang belum dikonfirmasi: target
+// masih dianggap up tapi run terakhir gagal ("" jika tidak ada)
+func (tu *TargetURL) GetPendingFailure() string {
+	if !tu.IsUp || tu.LastProbeUp {
+		return ""
+	}
+	return fmt.Sprintf("Gagal %d/%d run: %s", tu.ConsecutiveFailures, tu.GetFailureThreshold(), tu.failureReason())
+}
+
+// GetFailureThreshold mengembalikan ambang konfirmasi down (minimal 1)
+func (tu *TargetURL) GetFailureThreshold() int {
+	if tu.FailureThreshold < 1 {
+		return 1
+	}
+	return tu.FailureThreshold
+}
+
+// IsUnchecked bernilai true untuk target yang belum pernah diprobe
+func (tu *TargetURL) IsUnchecked() bool {
+	return tu.LastAttempts == 0
+}
+
+// ConfirmState menghitung status terkonfirmasi dari hasil mentah run
+// terbaru. Target langsung up saat probe berhasil, tapi baru down setelah
+// consecutiveFailures mencapai FailureThreshold.
+func (tu *TargetURL) ConfirmState(rawUp bool, consecutiveFailures int) bool {
+	if rawUp {
+		return true
+	}
+	return tu.IsUp && consecutiveFailures < tu.GetFailureThreshold()
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Resolusi data chart: data mentah probe_history atau rollup per jam/hari
+const (
+	ResolutionRaw    = "raw"
+	ResolutionHourly = "hourly"
+	ResolutionDaily  = "daily"
+)
+
+// ProbeRollup merangkum semua probe satu target dalam satu bucket waktu
+// (tabel probe_rollup_hourly dan probe_rollup_daily). Statistik latency
+// dihitung dari probe yang up saja agar timeout tidak merusak rata-rata.
+type ProbeRollup struct {
+	URLID    int
+	Bucket   time.Time
+	Count    int64
+	Failures int64
+	MinMs    int64
+	AvgMs    int64
+	MaxMs    int64
+	P50Ms    int64
+	P90Ms    int64
+	P95Ms    int64
+	P99Ms    int64
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultExpectedStatus adalah aturan status untuk target yang tidak
+// mengatur ExpectedStatus (perilaku lama: hanya 200 yang dianggap up).
+const DefaultExpectedStatus = "200"
+
+// statusRule adalah satu aturan status: rentang [min, max], bisa dinegasikan.
+type statusRule struct {
+	min, max int
+	negate   bool
+}
+
+func (r statusRule) matches(code int) bool {
+	return code >= r.min && code <= r.max
+}
+
+// ValidateStatusRules memeriksa apakah teks aturan status valid
+func ValidateStatusRules(text string) error {
+	_, err := parseStatusRules(text)
+	return err
+}
+
+// parseStatusRules membaca aturan status yang dipisah koma, contoh:
+//
+//	"200"          hanya 200
+//	"2xx,301"      semua 2xx ditambah 301
+//	"200-399,!302" 200 sampai 399 kecuali 302
+//	"!5xx"         semua kecuali 5xx
+func parseStatusRules(text string) ([]statusRule, error) {
+	var rules []statusRule
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		rule := statusRule{}
+		if strings.HasPrefix(part, "!") {
+			rule.negate = true
+			part = strings.TrimSpace(part[1:])
+		}
+
+		lower := strings.ToLower(part)
+		switch {
+		case len(lower) == 3 && strings.HasSuffix(lower, "xx"):
+			class, err := strconv.Atoi(lower[:1])
+			if err != nil || class < 1 || class > 5 {
+				return nil, fmt.Errorf("kelas status tidak valid: %q", part)
+			}
+			rule.min, rule.max = class*100, class*100+99
+		case strings.Contains(part, "-"):
+			from, to, _ := strings.Cut(part, "-")
+			min, err1 := parseStatusCode(from)
+			max, err2 := parseStatusCode(to)
+			if err1 != nil || err2 != nil || min > max {
+				return nil, fmt.Errorf("rentang status tidak valid: %q", part)
+			}
+			rule.min, rule.max = min, max
+		default:
+			code, err := parseStatusCode(part)
+			if err != nil {
+				return nil, err
+			}
+			rule.min, rule.max = code, code
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("aturan status kosong")
+	}
+	return rules, nil
+}
+
+func parseStatusCode(text string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("status code tidak valid: %q", text)
+	}
+	return code, nil
+}
+
+// statusMatches mengevaluasi status code terhadap aturan. Status diterima jika
+// cocok dengan salah satu aturan positif (atau tidak ada aturan positif) dan
+// tidak cocok dengan aturan negasi mana pun. Status 0 (network error) tidak
+// pernah diterima.
+func statusMatches(rules []statusRule, code int) bool {
+	if code <= 0 {
+		return false
+	}
+	hasPositive, positiveMatch := false, false
+	for _, r := range rules {
+		if r.negate {
+			if r.matches(code) {
+				return false
+			}
+			continue
+		}
+		hasPositive = true
+		if r.matches(code) {
+			positiveMatch = true
+		}
+	}
+	return positiveMatch || !hasPositive
+}
+
+// StatusAccepted adalah satu-satunya tempat yang menentukan apakah status
+// code dianggap "up" untuk target ini. Dipakai oleh scheduler dan database.
+func (tu *TargetURL) StatusAccepted(code int) bool {
+	rules, _ := parseStatusRules(tu.effectiveExpectedStatus())
+	return statusMatches(rules, code)
+}
+
+// effectiveExpectedStatus mengembalikan aturan status yang benar-benar
+// dipakai: ExpectedStatus, atau DefaultExpectedStatus jika kosong atau rusak
+func (tu *TargetURL) effectiveExpectedStatus() string {
+	text := strings.TrimSpace(tu.ExpectedStatus)
+	if text == "" {
+		return DefaultExpectedStatus
+	}
+	if _, err := parseStatusRules(text); err != nil {
+		// Aturan rusak di DB: kembali ke perilaku default
+		return DefaultExpectedStatus
+	}
+	return text
+}
@@ -0,0 +1,75 @@
+package models
+
+import "testing"
+
+func TestStatusAccepted(t *testing.T) {
+	tests := []struct {
+		rules  string
+		accept []int
+		reject []int
+	}{
+		{"", []int{200}, []int{0, 201, 204, 301, 500}},
+		{"200", []int{200}, []int{0, 201, 404}},
+		{"204", []int{204}, []int{200}},
+		{"2xx", []int{200, 204, 299}, []int{199, 300, 404}},
+		{"2XX, 301", []int{200, 250, 301}, []int{302, 500}},
+		{"200-399", []int{200, 302, 399}, []int{199, 400}},
+		{"200-399,!302", []int{200, 301, 399}, []int{302, 404}},
+		{"2xx,3xx,!304", []int{200, 301}, []int{304, 404}},
+		{"!5xx", []int{100, 200, 404, 499}, []int{0, 500, 503, 599}},
+		{"!503, !404", []int{200, 500}, []int{404, 503}},
+		{" 200 , , 201 ", []int{200, 201}, []int{202}},
+		// Aturan rusak di database kembali ke default "200"
+		{"abc", []int{200}, []int{201}},
+	}
+	for _, tt := range tests {
+		target := TargetURL{ExpectedStatus: tt.rules}
+		for _, code := range tt.accept {
+			if !target.StatusAccepted(code) {
+				t.Errorf("%q: status %d rejected, want accepted", tt.rules, code)
+			}
+		}
+		for _, code := range tt.reject {
+			if target.StatusAccepted(code) {
+				t.Errorf("%q: status %d accepted, want rejected", tt.rules, code)
+			}
+		}
+	}
+}
+
+func TestValidateStatusRules(t *testing.T) {
+	valid := []string{"200", "2xx", "1xx,5xx", "200-399", "!503", "! 5xx", "200 - 204", "100,599"}
+	for _, text := range valid {
+		if err := ValidateStatusRules(text); err != nil {
+			t.Errorf("ValidateStatusRules(%q) = %v, want nil", text, err)
+		}
+	}
+
+	invalid := []string{
+		"", " , ", "abc", "99", "600", "0xx", "6xx", "2x", "2xxx",
+		"399-200", "200-", "-200", "200-abc", "!", "!abc", "20 0",
+	}
+	for _, text := range invalid {
+		if err := ValidateStatusRules(text); err == nil {
+			t.Errorf("ValidateStatusRules(%q) = nil, want error", text)
+		}
+	}
+}
+
+func TestStatusMismatchShowsEffectiveRule(t *testing.T) {
+	tests := []struct {
+		rules string
+		want  string
+	}{
+		{"", "Status 503 tidak sesuai aturan 200"},
+		{"  ", "Status 503 tidak sesuai aturan 200"},
+		{"abc", "Status 503 tidak sesuai aturan 200"},
+		{"2xx,!204", "Status 503 tidak sesuai aturan 2xx,!204"},
+	}
+	for _, tt := range tests {
+		target := TargetURL{ExpectedStatus: tt.rules}
+		if got := target.StatusMismatch(503); got != tt.want {
+			t.Errorf("%q: StatusMismatch = %q, want %q", tt.rules, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package models
+
+import "fmt"
+
+// Tingkat latency target terhadap ambang warning/critical
+const (
+	LatencyOK       = ""
+	LatencyWarning  = "warning"
+	LatencyCritical = "critical"
+)
+
+// Batas input form ambang latency
+const (
+	MaxLatencyThresholdMs = 600000
+	MaxLatencyAvgProbes   = 100
+)
+
+// ValidateLatencyThresholds memeriksa bahwa ambang critical tidak lebih kecil
+// dari ambang warning jika keduanya diisi (0 = nonaktif)
+func ValidateLatencyThresholds(warnMs, critMs int) error {
+	if warnMs > 0 && critMs > 0 && critMs < warnMs {
+		return fmt.Errorf("ambang critical (%d ms) lebih kecil dari warning (%d ms)", critMs, warnMs)
+	}
+	return nil
+}
+
+// HasLatencyThresholds bernilai true jika target punya ambang latency
+func (tu *TargetURL) HasLatencyThresholds() bool {
+	return tu.LatencyWarnMs > 0 || tu.LatencyCritMs > 0
+}
+
+// GetLatencyAvgProbes mengembalikan jumlah probe untuk rata-rata latency
+// (minimal 1 = probe tunggal)
+func (tu *TargetURL) GetLatencyAvgProbes() int {
+	if tu.LatencyAvgProbes < 1 {
+		return 1
+	}
+	return tu.LatencyAvgProbes
+}
+
+// LatencyLevelFor menentukan tingkat latency untuk nilai ms berdasarkan
+// ambang target
+func (tu *TargetURL) LatencyLevelFor(ms int64) string {
+	switch {
+	case tu.LatencyCritMs > 0 && ms >= int64(tu.LatencyCritMs):
+		return LatencyCritical
+	case tu.LatencyWarnMs > 0 && ms >= int64(tu.LatencyWarnMs):
+		return LatencyWarning
+	}
+	return LatencyOK
+}
+
+// IsLatencyDegraded bernilai true jika target up tapi latency-nya melewati
+// ambang warning atau critical
+func (tu *TargetURL) IsLatencyDegraded() bool {
+	return tu.IsUp && tu.LatencyLevel != LatencyOK
+}
+
+// IsLatencyCritical bernilai true jika target up tapi latency-nya melewati
+// ambang critical
+func (tu *TargetURL) IsLatencyCritical() bool {
+	return tu.IsUp && tu.LatencyLevel == LatencyCritical
+}
+
+// AlertState mengembalikan status target untuk alert: StateDown,
+// StateDegraded (latency) atau StateUp
+func (tu *TargetURL) AlertState() string {
+	switch {
+	case !tu.IsUp:
+		return StateDown
+	case tu.LatencyLevel != LatencyOK:
+		return StateDegraded
+	}
+	return StateUp
+}
+
+// LatencyReason menjelaskan kenapa latency ms dianggap level, mis.
+// "Latency rata-rata 5 probe 1200 ms ≥ warning 1000 ms"
+func (tu *TargetURL) LatencyReason(level string, ms int64) string {
+	threshold := tu.LatencyWarnMs
+	if level == LatencyCritical {
+		threshold = tu.LatencyCritMs
+	}
+	subject := "Latency"
+	if n := tu.GetLatencyAvgProbes(); n > 1 {
+		subject = fmt.Sprintf("Latency rata-rata %d probe", n)
+	}
+	return fmt.Sprintf("%s %d ms ≥ %s %d ms", subject, ms, level, threshold)
+}
+
+// GetLatencyThresholds meringkas ambang latency untuk tabel URL ("" jika
+// tidak ada)
+func (tu *TargetURL) GetLatencyThresholds() string {
+	if !tu.HasLatencyThresholds() {
+		return ""
+	}
+	text := ""
+	if tu.LatencyWarnMs > 0 {
+		text = fmt.Sprintf("warn %d ms", tu.LatencyWarnMs)
+	}
+	if tu.LatencyCritMs > 0 {
+		if text != "" {
+			text += " / "
+		}
+		text += fmt.Sprintf("crit %d ms", tu.LatencyCritMs)
+	}
+	if n := tu.GetLatencyAvgProbes(); n > 1 {
+		text += fmt.Sprintf(" (avg %d)", n)
+	}
+	return text
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"html/template"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,82 @@ type TargetURL struct {
 	FirstUpTime     sql.NullTime
 	TotalProbeCount int64
 	TotalLatencySum int64
+
+	// ProbeType menentukan cara target diprobe (ProbeHTTP, ProbeTCP, ProbeDNS,
+	// atau jenis lain yang didaftarkan lewat probe.Register)
+	ProbeType string
+
+	// Konfigurasi request yang dikirim saat probe
+	Method  string
+	Headers map[string]string
+	Body    string
+
+	// ExpectedStatus adalah aturan status yang dianggap up, lihat ValidateStatusRules
+	ExpectedStatus string
+
+	// Assertions adalah pemeriksaan body response, lihat ParseAssertions
+	Assertions []Assertion
+	// LastAssertions adalah hasil setiap assertion pada probe terakhir
+	LastAssertions []AssertionResult
+	// LastError adalah alasan target down pada probe terakhir (mis. assertion
+	// gagal atau error jaringan), LastErrorClass adalah kode jenis kegagalannya
+	LastError      string
+	LastErrorClass ErrorClass
+
+	// Info sertifikat TLS dari probe HTTPS terakhir
+	CertExpiry   sql.NullTime
+	CertIssuer   string
+	CertSANs     []string
+	CertWarnDays int
+	// Konfigurasi probe TCP: data yang dikirim dan banner yang diharapkan
+	TCPSend   string
+	TCPExpect string
+
+	// Konfigurasi probe DNS: jenis record, resolver (host:port) dan nilai yang diharapkan
+	DNSRecordType string
+	DNSResolver   string
+	DNSExpected   string
+
+	// Konfirmasi kegagalan: RetryCount percobaan ulang dalam satu run (jeda
+	// RetryDelayMs), dan target baru dianggap down setelah FailureThreshold
+	// run berturut-turut gagal
+	RetryCount       int
+	RetryDelayMs     int
+	FailureThreshold int
+	// Hasil mentah run terakhir: up/tidak, jumlah percobaan dan jumlah run
+	// gagal berturut-turut. IsUp adalah status yang sudah dikonfirmasi.
+	LastProbeUp         bool
+	LastAttempts        int
+	ConsecutiveFailures int
+
+	// RetentionDays adalah lama history target disimpan (hari). 0 berarti
+	// ikut setting global history_retention_days.
+	RetentionDays int
+
+	// SLATarget adalah target availability (persen) target ini. 0 berarti
+	// ikut setting global sla_target.
+	SLATarget float64
+
+	// CheckInterval adalah jadwal cek khusus target ini (mis. "@every 30s" atau
+	// ekspresi cron). Kosong berarti ikut interval global.
+	CheckInterval string
+
+	// Ambang latency (ms, 0 = nonaktif): target up yang latency-nya mencapai
+	// LatencyWarnMs atau LatencyCritMs dianggap degraded. Jika
+	// LatencyAvgProbes > 1, yang dibandingkan adalah rata-rata latency N
+	// probe up terakhir, bukan probe tunggal.
+	LatencyWarnMs    int
+	LatencyCritMs    int
+	LatencyAvgProbes int
+	// LatencyLevel adalah tingkat latency run terakhir (LatencyOK,
+	// LatencyWarning atau LatencyCritical), LatencyCheckMs nilai yang
+	// dibandingkan dengan ambang
+	LatencyLevel   string
+	LatencyCheckMs int64
+
+	// IsDegraded: target up tapi butuh perhatian (mis. sertifikat hampir
+	// kadaluarsa atau latency melewati ambang)
+	IsDegraded bool
 }
 
 type ProbeHistory struct {
@@ -24,28 +102,116 @@ type ProbeHistory struct {
 	URL       string
 	LatencyMs int64
 	Timestamp time.Time
+	PhaseTimings
+
+	// Hasil lengkap probe: status code, verdict mentah, jumlah percobaan dan
+	// kelas serta pesan error jika gagal (lihat ErrorClass)
+	StatusCode   int
+	IsUp         bool
+	Attempts     int
+	ErrorClass   ErrorClass
+	ErrorMessage string
+}
+
+// ProbeOutcome adalah hasil satu run probe yang disimpan ke database (dipetakan
+// dari probe.ProbeResult oleh scheduler)
+type ProbeOutcome struct {
+	StatusCode int
+	LatencyMs  int64
+	// Up adalah verdict mentah probe, sebelum dikonfirmasi (lihat CheckState)
+	Up              bool
+	FailedAssertion string
+	Assertions      []AssertionResult
+	Timings         PhaseTimings
+	Attempts        int
+	ErrorClass      ErrorClass
+	ErrorMessage    string
+}
+
+// PhaseTimings adalah rincian latency per fase request (milidetik)
+type PhaseTimings struct {
+	DNSMs      int64
+	ConnectMs  int64
+	TLSMs      int64
+	TTFBMs     int64
+	TransferMs int64
+}
+
+// Cakupan run scheduler: job global (semua target yang ikut interval
+// global), job milik satu target dengan interval sendiri, atau probe manual
+// dari tombol "Probe Now"
+const (
+	RunScopeGlobal = "global"
+	RunScopeTarget = "target"
+	RunScopeManual = "manual"
+)
+
+// SchedulerRun merangkum satu kali eksekusi job probe (tabel scheduler_runs)
+type SchedulerRun struct {
+	ID         int64
+	Scope      string
+	URLID      int    // untuk scope target dan probe manual satu target
+	URL        string // untuk scope target dan probe manual satu target
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Targets    int
+	Failures   int
+	// Skipped: run dilewati karena run sebelumnya masih berjalan
+	Skipped bool
+	// Error: run gagal sebelum target diprobe (mis. daftar target tidak bisa dibaca)
+	Error string
+}
+
+// GetDuration mengembalikan durasi run yang dibulatkan untuk ditampilkan
+func (r SchedulerRun) GetDuration() string {
+	return r.FinishedAt.Sub(r.StartedAt).Round(time.Millisecond).String()
 }
 
 type PageData struct {
-	Page             string
-	URLs             []TargetURL
-	CurrentInterval  string
-	GlobalAvgLatency int64
-	GlobalUptimePct  int
-	LastCheckedTime  time.Time
-	HistoryData      []ProbeHistory
-	SelectedURLID    int
-	PageNumber       int
-	PageSize         int
-	TotalItems       int64
-	TotalPages       int
-	HasPrev          bool
-	HasNext          bool
-	PrevPage         int
-	NextPage         int
-	ChartRange       string
-	NavigatorPages   []int
-	JSONHistoryData  template.JS
+	Page                string
+	URLs                []TargetURL
+	DownURLs            []TargetURL
+	DegradedURLs        []TargetURL
+	CurrentInterval     string
+	GlobalAvgLatency    int64
+	GlobalUptimePct     int
+	LastCheckedTime     time.Time
+	HistoryData         []ProbeHistory
+	SelectedURLID       int
+	PageNumber          int
+	PageSize            int
+	TotalItems          int64
+	TotalPages          int
+	HasPrev             bool
+	HasNext             bool
+	PrevPage            int
+	NextPage            int
+	ChartRange          string
+	NavigatorPages      []int
+	JSONHistoryData     template.JS
+	RollupData          []ProbeRollup
+	JSONRollupData      template.JS
+	ChartResolution     string
+	LatencyWindow       string
+	LatencyWindows      []string
+	GlobalLatency       LatencyPercentiles
+	TargetLatency       map[int]LatencyPercentiles
+	ProbeTypes          []string
+	LastRun             SchedulerRun
+	SchedulerRuns       []SchedulerRun
+	Concurrency         int
+	PerHostLimit        int
+	RetentionDays       int
+	SLATarget           float64
+	GlobalAvailability  Availability
+	TargetAvailability  map[int]AvailabilitySet
+	AvailabilityWindows []string
+	Incidents           []Incident
+	SMTP                SMTPConfig
+	Channels            []NotificationChannel
+	ChannelTypes        []string
+	Deliveries          []NotificationDelivery
+	PublicURL           string
 }
 
 // === FUNGSI HELPER UNTUK TEMPLATE ===
@@ -82,3 +248,57 @@ func (tu *TargetURL) GetAverageLatency() string {
 	avg := tu.TotalLatencySum / tu.TotalProbeCount
 	return fmt.Sprintf("%d ms", avg)
 }
+
+// GetHeaderNames mengembalikan nama header target, satu per baris. Nilainya
+// sengaja tidak ditampilkan karena bisa berisi token atau kredensial.
+func (tu *TargetURL) GetHeaderNames() string {
+	keys := make([]string, 0, len(tu.Headers))
+	for k := range tu.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\n")
+}
+
+// ParseHeaderLines mengubah teks "Key: Value" (satu header per baris) menjadi map.
+// Baris kosong diabaikan.
+func ParseHeaderLines(text string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("header tidak valid: %q (format: Key: Value)", line)
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// GetDownReason menjelaskan kenapa target dianggap down ("" jika up)
+func (tu *TargetURL) GetDownReason() string {
+	if tu.IsUp {
+		return ""
+	}
+	return tu.failureReason()
+}
+
+// failureReason menjelaskan kegagalan run terakhir dari status dan error
+func (tu *TargetURL) failureReason() string {
+	if tu.LastError != "" {
+		return tu.LastError
+	}
+	if tu.LastStatus == 0 || !tu.IsHTTP() {
+		return "Network error / timeout"
+	}
+	return tu.StatusMismatch(tu.LastStatus)
+}
+
+// StatusMismatch menjelaskan status code yang tidak sesuai ExpectedStatus
+func (tu *TargetURL) StatusMismatch(code int) string {
+	return fmt.Sprintf("Status %d tidak sesuai aturan %s", code, tu.effectiveExpectedStatus())
+}
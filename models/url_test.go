@@ -0,0 +1,22 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetHeaderNamesHidesValues(t *testing.T) {
+	target := TargetURL{Headers: map[string]string{
+		"X-Api-Key":     "k-123",
+		"Authorization": "Bearer secret-token",
+	}}
+	got := target.GetHeaderNames()
+	if got != "Authorization\nX-Api-Key" {
+		t.Errorf("GetHeaderNames() = %q, want %q", got, "Authorization\nX-Api-Key")
+	}
+	for _, value := range target.Headers {
+		if strings.Contains(got, value) {
+			t.Errorf("GetHeaderNames() leaks header value %q", value)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"test/models"
+	"time"
+)
+
+func init() {
+	Register(models.ChannelSlack, chatSender{format: slackPayload})
+	Register(models.ChannelDiscord, chatSender{format: discordPayload})
+}
+
+// Warna alert per tingkat keparahan
+const (
+	colorDown      = 0xE53935
+	colorCritical  = 0xF4511E
+	colorWarning   = 0xFB8C00
+	colorRecovered = 0x43A047
+	colorTest      = 0x1E88E5
+)
+
+// severityColor memilih warna alert sesuai status baru target
+func severityColor(event models.AlertEvent) int {
+	switch {
+	case event.Test:
+		return colorTest
+	case event.NewState == models.StateDown:
+		return colorDown
+	case event.NewState == models.StateDegraded && event.Level == models.LatencyCritical:
+		return colorCritical
+	case event.NewState == models.StateDegraded:
+		return colorWarning
+	default:
+		return colorRecovered
+	}
+}
+
+// alertField adalah satu pasangan label/nilai yang ditampilkan di pesan chat
+type alertField struct {
+	name, value string
+}
+
+// alertFields mengembalikan rincian alert yang sama untuk Slack dan Discord
+func alertFields(event models.AlertEvent) []alertField {
+	statusCode := "-"
+	if event.StatusCode != 0 {
+		statusCode = fmt.Sprint(event.StatusCode)
+	}
+	fields := []alertField{
+		{"Status", event.OldState + " → " + event.NewState},
+		{"Status code", statusCode},
+		{"Latency", fmt.Sprintf("%d ms", event.LatencyMs)},
+	}
+	if event.Level != "" {
+		fields = append(fields, alertField{"Level", event.Level})
+	}
+	if event.Error != "" {
+		errText := event.Error
+		if event.ErrorClass != "" {
+			errText = fmt.Sprintf("[%s] %s", event.ErrorClass, event.Error)
+		}
+		fields = append(fields, alertField{"Error", errText})
+	}
+	return fields
+}
+
+// chatSender mengirim alert ke incoming webhook platform chat. format
+// menyusun payload sesuai platform.
+type chatSender struct {
+	format func(event models.AlertEvent) any
+}
+
+func (chatSender) Validate(channel models.NotificationChannel) error {
+	return validateHTTPURL(channel.URL)
+}
+
+func (s chatSender) Send(ctx context.Context, channel models.NotificationChannel, event models.AlertEvent) (int, error) {
+	body, err := json.Marshal(s.format(event))
+	if err != nil {
+		return 0, Permanent(err)
+	}
+	return postJSON(ctx, channel.URL, body, nil)
+}
+
+// slackPayload menyusun pesan Block Kit. Blok diletakkan di dalam
+// attachment agar bisa diberi warna.
+func slackPayload(event models.AlertEvent) any {
+	title := fmt.Sprintf("%s: %s", event.Title(), event.URL)
+
+	fields := []map[string]any{}
+	for _, f := range alertFields(event) {
+		fields = append(fields, map[string]any{
+			"type": "mrkdwn",
+			"text": truncate(fmt.Sprintf("*%s*\n%s", f.name, f.value), 2000),
+		})
+	}
+
+	footer := fmt.Sprintf("fprobe • <!date^%d^{date_short_pretty} {time_secs}|%s>",
+		event.Timestamp.Unix(), event.Timestamp.Format(time.RFC1123))
+	if event.Link != "" {
+		footer = fmt.Sprintf("<%s|Buka di fprobe> • %s", event.Link, footer)
+	}
+
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": truncate(title, 150)},
+		},
+		{
+			"type":   "section",
+			"fields": fields,
+		},
+		{
+			"type": "context",
+			"elements": []map[string]any{
+				{"type": "mrkdwn", "text": footer},
+			},
+		},
+	}
+	return map[string]any{
+		// text dipakai untuk notifikasi push dan klien yang tidak mendukung blok
+		"text": title,
+		"attachments": []map[string]any{
+			{
+				"color":  fmt.Sprintf("#%06X", severityColor(event)),
+				"blocks": blocks,
+			},
+		},
+	}
+}
+
+// discordPayload menyusun pesan embed Discord
+func discordPayload(event models.AlertEvent) any {
+	fields := []map[string]any{}
+	for _, f := range alertFields(event) {
+		fields = append(fields, map[string]any{
+			"name":   f.name,
+			"value":  truncate(f.value, 1024),
+			"inline": f.name != "Error",
+		})
+	}
+
+	embed := map[string]any{
+		"title":     truncate(fmt.Sprintf("%s: %s", event.Title(), event.URL), 256),
+		"color":     severityColor(event),
+		"fields":    fields,
+		"timestamp": event.Timestamp.Format(time.RFC3339),
+		"footer":    map[string]any{"text": "fprobe"},
+	}
+	if event.Link != "" {
+		embed["url"] = event.Link
+	}
+	return map[string]any{
+		"username": "fprobe",
+		"embeds":   []map[string]any{embed},
+	}
+}
+
+// truncate memotong teks agar tidak melebihi batas panjang field platform
+// chat (dihitung dalam rune)
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
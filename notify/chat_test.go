@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"test/models"
+	"testing"
+	"unicode/utf8"
+)
+
+// deliverChat mengirim testEvent ke channel chat uji dan mengembalikan
+// payload JSON yang diterima server
+func deliverChat(t *testing.T, channelType string, event models.AlertEvent) map[string]any {
+	t.Helper()
+	n := newTestNotifier(t)
+	if err := n.store.SetSetting("public_url", "https://fprobe.example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	server, requests := startWebhookServer(t, http.StatusOK)
+
+	channel := models.NotificationChannel{Name: "ops", Type: channelType, URL: server.URL}
+	delivery := n.Deliver(channel, event)
+	if !delivery.Success || delivery.Attempts != 1 {
+		t.Fatalf("delivery = %+v, want success in 1 attempt", delivery)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1", len(got))
+	}
+	if ct := got[0].header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(got[0].body, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	return payload
+}
+
+func TestSlackPayload(t *testing.T) {
+	payload := deliverChat(t, models.ChannelSlack, testEvent())
+
+	if text := payload["text"]; text != "DOWN: https://api.example.com/health" {
+		t.Errorf("text = %v", text)
+	}
+	attachments, _ := payload["attachments"].([]any)
+	if len(attachments) != 1 {
+		t.Fatalf("attachments = %v, want 1", payload["attachments"])
+	}
+	attachment := attachments[0].(map[string]any)
+	if attachment["color"] != "#E53935" {
+		t.Errorf("color = %v, want #E53935", attachment["color"])
+	}
+
+	blocks, _ := attachment["blocks"].([]any)
+	if len(blocks) != 3 {
+		t.Fatalf("got %d blocks, want header, section and context", len(blocks))
+	}
+	header := blocks[0].(map[string]any)
+	if text := header["text"].(map[string]any)["text"]; header["type"] != "header" || text != "DOWN: https://api.example.com/health" {
+		t.Errorf("header block = %v", header)
+	}
+
+	var fields []string
+	for _, f := range blocks[1].(map[string]any)["fields"].([]any) {
+		fields = append(fields, f.(map[string]any)["text"].(string))
+	}
+	wantFields := []string{
+		"*Status*\nup → down",
+		"*Status code*\n503",
+		"*Latency*\n120 ms",
+		"*Error*\n[status] Status 503 tidak sesuai aturan 200",
+	}
+	if strings.Join(fields, "|") != strings.Join(wantFields, "|") {
+		t.Errorf("fields = %q, want %q", fields, wantFields)
+	}
+
+	footer := blocks[2].(map[string]any)["elements"].([]any)[0].(map[string]any)["text"].(string)
+	if !strings.HasPrefix(footer, "<https://fprobe.example.com/?url_id=7|Buka di fprobe>") {
+		t.Errorf("context = %q, want link to target", footer)
+	}
+}
+
+func TestDiscordPayload(t *testing.T) {
+	event := testEvent()
+	event.NewState = models.StateDegraded
+	event.Level = models.LatencyCritical
+	payload := deliverChat(t, models.ChannelDiscord, event)
+
+	if payload["username"] != "fprobe" {
+		t.Errorf("username = %v, want fprobe", payload["username"])
+	}
+	embeds, _ := payload["embeds"].([]any)
+	if len(embeds) != 1 {
+		t.Fatalf("embeds = %v, want 1", payload["embeds"])
+	}
+	embed := embeds[0].(map[string]any)
+	want := map[string]any{
+		"title":     "DEGRADED: https://api.example.com/health",
+		"color":     float64(colorCritical),
+		"timestamp": "2026-01-02T15:04:05Z",
+		"url":       "https://fprobe.example.com/?url_id=7",
+	}
+	for key, value := range want {
+		if embed[key] != value {
+			t.Errorf("embed[%q] = %v, want %v", key, embed[key], value)
+		}
+	}
+
+	var names []string
+	for _, f := range embed["fields"].([]any) {
+		field := f.(map[string]any)
+		names = append(names, field["name"].(string))
+		if inline := field["inline"] == true; inline == (field["name"] == "Error") {
+			t.Errorf("field %v: inline = %v", field["name"], field["inline"])
+		}
+	}
+	if got := strings.Join(names, ","); got != "Status,Status code,Latency,Level,Error" {
+		t.Errorf("field names = %s", got)
+	}
+}
+
+func TestChatPayloadTruncatesLongText(t *testing.T) {
+	event := testEvent()
+	event.URL = "https://example.com/" + strings.Repeat("é", 300)
+
+	header := deliverChat(t, models.ChannelSlack, event)["attachments"].([]any)[0].(map[string]any)["blocks"].([]any)[0]
+	if text := header.(map[string]any)["text"].(map[string]any)["text"].(string); utf8.RuneCountInString(text) != 150 || !strings.HasSuffix(text, "…") {
+		t.Errorf("slack header has %d runes, want 150 ending in …", utf8.RuneCountInString(text))
+	}
+
+	embed := deliverChat(t, models.ChannelDiscord, event)["embeds"].([]any)[0].(map[string]any)
+	if title := embed["title"].(string); utf8.RuneCountInString(title) != 256 || !strings.HasSuffix(title, "…") {
+		t.Errorf("discord title has %d runes, want 256 ending in …", utf8.RuneCountInString(title))
+	}
+}
+
+func TestChatDeliveryErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		codes        []int
+		wantSuccess  bool
+		wantAttempts int
+	}{
+		{"server error lalu sukses", []int{http.StatusServiceUnavailable, http.StatusOK}, true, 2},
+		{"server error terus", []int{http.StatusBadGateway}, false, DefaultMaxAttempts},
+		{"client error tidak diulang", []int{http.StatusNotFound, http.StatusOK}, false, 1},
+	}
+	for _, channelType := range []string{models.ChannelSlack, models.ChannelDiscord} {
+		for _, tt := range tests {
+			t.Run(channelType+"/"+tt.name, func(t *testing.T) {
+				n := newTestNotifier(t)
+				server, requests := startWebhookServer(t, tt.codes...)
+
+				channel := models.NotificationChannel{Name: "ops", Type: channelType, URL: server.URL}
+				delivery := n.Deliver(channel, testEvent())
+				if delivery.Success != tt.wantSuccess || delivery.Attempts != tt.wantAttempts {
+					t.Errorf("delivery = %+v, want success=%v after %d attempts", delivery, tt.wantSuccess, tt.wantAttempts)
+				}
+				if !tt.wantSuccess && delivery.Error == "" {
+					t.Error("failed delivery has no error message")
+				}
+				if len(requests()) != tt.wantAttempts {
+					t.Errorf("got %d requests, want %d", len(requests()), tt.wantAttempts)
+				}
+			})
+		}
+	}
+}
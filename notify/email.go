@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"test/models"
+	"text/template"
+	"time"
+)
+
+func init() {
+	Register(models.ChannelEmail, emailSender{})
+}
+
+// emailSubject dan emailBody adalah template email down/degraded/pulih
+var (
+	emailSubject = template.Must(template.New("subject").Parse(
+		`[fprobe] {{.Title}}: {{.URL}}`))
+	emailBody = template.Must(template.New("body").Parse(`{{if .Test}}Ini adalah email percobaan dari fprobe.
+{{else if eq .NewState "down"}}Target {{.URL}} terdeteksi DOWN.
+{{else if eq .NewState "degraded"}}Target {{.URL}} melambat (DEGRADED, {{.Level}}).
+{{else if eq .OldState "degraded"}}Latency target {{.URL}} sudah kembali normal (UP).
+{{else}}Target {{.URL}} sudah pulih (UP).
+{{end}}
+Target      : {{.URL}} ({{.ProbeType}})
+Status      : {{.OldState}} -> {{.NewState}}
+Status code : {{if .StatusCode}}{{.StatusCode}}{{else}}-{{end}}
+Latency     : {{.LatencyMs}} ms
+{{- if .Error}}
+Error       : {{with .ErrorClass}}[{{.}}] {{end}}{{.Error}}
+{{- end}}
+Waktu       : {{.Timestamp.Format "2006-01-02 15:04:05 MST"}}
+{{- with .Link}}
+Detail      : {{.}}
+{{- end}}
+
+--
+fprobe
+`))
+)
+
+// emailSender mengirim alert sebagai email lewat server SMTP dari config.
+// Penerima diambil dari daftar pada channel, atau setting smtp_to. config
+// diisi oleh Notifier yang mengirim (lihat Notifier.SMTPConfig).
+type emailSender struct {
+	config func() (models.SMTPConfig, error)
+}
+
+func (emailSender) Validate(channel models.NotificationChannel) error {
+	for _, addr := range models.SplitAddresses(channel.URL) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("alamat email tidak valid %q: %w", addr, err)
+		}
+	}
+	return nil
+}
+
+func (s emailSender) Send(ctx context.Context, channel models.NotificationChannel, event models.AlertEvent) (int, error) {
+	if s.config == nil {
+		return 0, Permanent(errors.New("sumber pengaturan SMTP belum diatur"))
+	}
+	cfg, err := s.config()
+	if err != nil {
+		return 0, err
+	}
+	if !cfg.Configured() {
+		return 0, Permanent(errors.New("server SMTP belum diatur"))
+	}
+	to := models.SplitAddresses(channel.URL)
+	if len(to) == 0 {
+		to = cfg.To
+	}
+	if len(to) == 0 {
+		return 0, Permanent(errors.New("tidak ada penerima email"))
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return 0, Permanent(fmt.Errorf("alamat pengirim tidak valid: %w", err))
+	}
+	// RCPT TO hanya menerima alamat tanpa nama tampilan
+	rcpt := make([]string, 0, len(to))
+	for _, addr := range to {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return 0, Permanent(fmt.Errorf("alamat penerima tidak valid: %w", err))
+		}
+		rcpt = append(rcpt, parsed.Address)
+	}
+	msg, err := buildEmail(cfg.From, to, event)
+	if err != nil {
+		return 0, Permanent(err)
+	}
+	return 0, sendMail(ctx, cfg, from.Address, rcpt, msg)
+}
+
+// buildEmail menyusun pesan email (header + body teks) dari template
+func buildEmail(from string, to []string, event models.AlertEvent) ([]byte, error) {
+	var subject, body bytes.Buffer
+	if err := emailSubject.Execute(&subject, event); err != nil {
+		return nil, err
+	}
+	if err := emailBody.Execute(&body, event); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject.String()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body.String(), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
+
+// sendMail mengirim pesan lewat SMTP: STARTTLS (jika diaktifkan) lalu AUTH
+// PLAIN (jika username diisi). Batas waktu mengikuti ctx.
+func sendMail(ctx context.Context, cfg models.SMTPConfig, from string, to []string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Addr())
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if cfg.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return Permanent(errors.New("server SMTP tidak mendukung STARTTLS"))
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return Permanent(fmt.Errorf("autentikasi SMTP gagal: %w", err))
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return Permanent(fmt.Errorf("penerima %s ditolak: %w", addr, err))
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
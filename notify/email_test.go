@@ -0,0 +1,229 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"strings"
+	"test/models"
+	"testing"
+	"time"
+)
+
+// smtpSession adalah rekaman satu koneksi ke server SMTP uji
+type smtpSession struct {
+	commands []string
+	data     string
+}
+
+// startSMTPServer menjalankan server SMTP uji di 127.0.0.1 yang menerima
+// semua perintah. Setiap sesi yang selesai dikirim ke channel.
+func startSMTPServer(t *testing.T, extensions ...string) (string, int, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan smtpSession, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, extensions, sessions)
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, sessions
+}
+
+func serveSMTP(conn net.Conn, extensions []string, sessions chan<- smtpSession) {
+	defer conn.Close()
+	var session smtpSession
+	defer func() { sessions <- session }()
+
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		conn.Write([]byte(strings.Join(lines, "\r\n") + "\r\n"))
+	}
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		session.commands = append(session.commands, line)
+
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+		switch verb {
+		case "EHLO":
+			lines := []string{"250-localhost"}
+			for _, ext := range extensions {
+				lines = append(lines, "250-"+ext)
+			}
+			reply(append(lines, "250 8BITMIME")...)
+		case "RCPT":
+			// Seperti server sungguhan, tolak alamat yang masih berisi nama tampilan
+			if strings.Count(line, "<") > 1 {
+				reply("501 5.1.3 Bad recipient address syntax")
+				continue
+			}
+			reply("250 2.1.5 Ok")
+		case "AUTH":
+			reply("235 2.7.0 Authentication successful")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			session.data = data.String()
+			reply("250 2.0.0 Ok: queued")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("250 2.0.0 Ok")
+		}
+	}
+}
+
+func waitSession(t *testing.T, sessions <-chan smtpSession) smtpSession {
+	t.Helper()
+	select {
+	case s := <-sessions:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP session did not finish")
+		return smtpSession{}
+	}
+}
+
+// hasCommand mengecek apakah sesi berisi perintah dengan prefix tertentu
+func hasCommand(s smtpSession, prefix string) bool {
+	for _, c := range s.commands {
+		if strings.HasPrefix(c, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEmailDeliversDownAndRecovered(t *testing.T) {
+	host, port, sessions := startSMTPServer(t, "AUTH PLAIN")
+	n := newTestNotifier(t)
+	err := n.store.SaveSMTPConfig(models.SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: "fprobe",
+		Password: "rahasia",
+		From:     "fprobe <alerts@example.com>",
+		To:       []string{"ops@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	down := testEvent()
+	recovered := testEvent()
+	recovered.OldState, recovered.NewState = models.StateDown, models.StateUp
+	recovered.StatusCode, recovered.Error, recovered.ErrorClass = 200, "", ""
+
+	tests := []struct {
+		name        string
+		channelURL  string
+		event       models.AlertEvent
+		wantRcpt    []string
+		wantSubject string
+	}{
+		{"down ke penerima bawaan", "", down, []string{"ops@example.com"},
+			"Subject: [fprobe] DOWN: https://api.example.com/health"},
+		{"recovered ke penerima channel", "dev@example.com, sre@example.com", recovered, []string{"dev@example.com", "sre@example.com"},
+			"Subject: [fprobe] RECOVERED: https://api.example.com/health"},
+		{"penerima dengan nama tampilan", "Tim Ops <ops-team@example.com>", down, []string{"ops-team@example.com"},
+			"Subject: [fprobe] DOWN: https://api.example.com/health"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := models.NotificationChannel{Name: "mail", Type: models.ChannelEmail, URL: tt.channelURL}
+			delivery := n.Deliver(channel, tt.event)
+			if !delivery.Success || delivery.Attempts != 1 {
+				t.Fatalf("delivery = %+v, want success in 1 attempt", delivery)
+			}
+			session := waitSession(t, sessions)
+
+			wantAuth := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00fprobe\x00rahasia"))
+			if !hasCommand(session, wantAuth) {
+				t.Errorf("missing %q in %q", wantAuth, session.commands)
+			}
+			if !hasCommand(session, "MAIL FROM:<alerts@example.com>") {
+				t.Errorf("missing MAIL FROM in %q", session.commands)
+			}
+			for _, rcpt := range tt.wantRcpt {
+				if !hasCommand(session, "RCPT TO:<"+rcpt+">") {
+					t.Errorf("missing RCPT TO:<%s> in %q", rcpt, session.commands)
+				}
+			}
+			if !hasCommand(session, "DATA") || !hasCommand(session, "QUIT") {
+				t.Errorf("missing DATA/QUIT in %q", session.commands)
+			}
+			if !strings.Contains(session.data, tt.wantSubject+"\r\n") {
+				t.Errorf("message has no %q:\n%s", tt.wantSubject, session.data)
+			}
+			if !strings.Contains(session.data, "Status      : "+tt.event.OldState+" -> "+tt.event.NewState) {
+				t.Errorf("message body has no status line:\n%s", session.data)
+			}
+		})
+	}
+}
+
+func TestEmailWithoutAuthSkipsAuth(t *testing.T) {
+	host, port, sessions := startSMTPServer(t)
+	n := newTestNotifier(t)
+	n.SMTPConfig = func() (models.SMTPConfig, error) {
+		return models.SMTPConfig{Host: host, Port: port, From: "alerts@example.com", To: []string{"ops@example.com"}}, nil
+	}
+
+	delivery := n.Deliver(models.NotificationChannel{Name: "mail", Type: models.ChannelEmail}, testEvent())
+	if !delivery.Success {
+		t.Fatalf("delivery failed: %s", delivery.Error)
+	}
+	if session := waitSession(t, sessions); hasCommand(session, "AUTH") {
+		t.Errorf("unexpected AUTH in %q", session.commands)
+	}
+}
+
+func TestEmailStartTLSRequired(t *testing.T) {
+	host, port, sessions := startSMTPServer(t)
+	n := newTestNotifier(t)
+	n.SMTPConfig = func() (models.SMTPConfig, error) {
+		return models.SMTPConfig{Host: host, Port: port, StartTLS: true, From: "alerts@example.com", To: []string{"ops@example.com"}}, nil
+	}
+
+	delivery := n.Deliver(models.NotificationChannel{Name: "mail", Type: models.ChannelEmail}, testEvent())
+	if delivery.Success || delivery.Attempts != 1 {
+		t.Fatalf("delivery = %+v, want permanent failure on attempt 1", delivery)
+	}
+	if session := waitSession(t, sessions); hasCommand(session, "MAIL") {
+		t.Errorf("mail sent without STARTTLS: %q", session.commands)
+	}
+}
+
+func TestEmailNotConfigured(t *testing.T) {
+	n := newTestNotifier(t)
+	delivery := n.Deliver(models.NotificationChannel{Name: "mail", Type: models.ChannelEmail, URL: "ops@example.com"}, testEvent())
+	if delivery.Success || delivery.Attempts != 1 {
+		t.Errorf("delivery = %+v, want permanent failure on attempt 1", delivery)
+	}
+}
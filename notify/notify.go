@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"test/database"
+	"test/models"
+	"time"
+)
+
+// Batas pengiriman satu alert ke satu channel
+const (
+	DefaultMaxAttempts = 3
+	DefaultRetryDelay  = 2 * time.Second
+	sendTimeout        = 10 * time.Second
+)
+
+// Sender mengirim alert ke satu jenis channel (webhook, email, ...).
+// Didaftarkan dengan Register dari init() file yang menyediakannya.
+type Sender interface {
+	// Validate memeriksa pengaturan channel sebelum disimpan
+	Validate(channel models.NotificationChannel) error
+	// Send mengirim satu alert. statusCode diisi jika tujuan merespons
+	// (mis. HTTP status), 0 jika tidak relevan.
+	Send(ctx context.Context, channel models.NotificationChannel, event models.AlertEvent) (statusCode int, err error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Sender{}
+)
+
+// Register mendaftarkan jenis channel baru; panic jika nama sudah terdaftar
+func Register(channelType string, sender Sender) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if sender == nil {
+		panic("notify: Register sender is nil for " + channelType)
+	}
+	if _, dup := registry[channelType]; dup {
+		panic("notify: Register called twice for " + channelType)
+	}
+	registry[channelType] = sender
+}
+
+// Types mengembalikan semua jenis channel yang terdaftar (urut alfabet)
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func senderFor(channelType string) (Sender, error) {
+	registryMu.RLock()
+	sender, ok := registry[channelType]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("jenis channel tidak dikenal: %q", channelType)
+	}
+	return sender, nil
+}
+
+// Validate memeriksa pengaturan channel sesuai jenisnya
+func Validate(channel models.NotificationChannel) error {
+	sender, err := senderFor(channel.Type)
+	if err != nil {
+		return err
+	}
+	return sender.Validate(channel)
+}
+
+// permanentError menandai kegagalan yang tidak akan berhasil jika diulang
+// (mis. HTTP 4xx), sehingga retry dihentikan
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent membungkus err agar pengiriman tidak diulang
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Notifier mengirim alert ke semua channel aktif dan mencatat hasilnya di
+// log pengiriman
+type Notifier struct {
+	store       *database.Store
+	MaxAttempts int
+	RetryDelay  time.Duration
+	// SMTPConfig membaca pengaturan server SMTP untuk channel email
+	SMTPConfig func() (models.SMTPConfig, error)
+}
+
+// New membuat Notifier dengan batas retry bawaan. Channel email membaca
+// pengaturan SMTP dari store yang sama.
+func New(store *database.Store) *Notifier {
+	return &Notifier{
+		store:       store,
+		MaxAttempts: DefaultMaxAttempts,
+		RetryDelay:  DefaultRetryDelay,
+		SMTPConfig:  store.GetSMTPConfig,
+	}
+}
+
+// senderFor mengembalikan sender untuk jenis channel. Sender email diberi
+// sumber pengaturan SMTP milik Notifier ini.
+func (n *Notifier) senderFor(channelType string) (Sender, error) {
+	sender, err := senderFor(channelType)
+	if email, ok := sender.(emailSender); ok {
+		email.config = n.SMTPConfig
+		return email, nil
+	}
+	return sender, err
+}
+
+// Notify mengirim alert ke semua channel aktif di background, sehingga
+// probe tidak tertahan oleh channel yang lambat. Aman dipanggil pada
+// Notifier nil.
+func (n *Notifier) Notify(event models.AlertEvent) {
+	if n == nil {
+		return
+	}
+	channels, err := n.store.GetChannels(true)
+	if err != nil {
+		log.Printf("[NOTIFY] Cannot load channels: %v\n", err)
+		return
+	}
+	for _, channel := range channels {
+		go n.Deliver(channel, event)
+	}
+}
+
+// Deliver mengirim satu alert ke satu channel lalu mencatat hasilnya di log
+// pengiriman
+func (n *Notifier) Deliver(channel models.NotificationChannel, event models.AlertEvent) models.NotificationDelivery {
+	delivery := models.NotificationDelivery{
+		ChannelID:   channel.ID,
+		ChannelName: channel.Name,
+		URLID:       event.URLID,
+		URL:         event.URL,
+		Event:       event.Name(),
+		CreatedAt:   time.Now(),
+	}
+
+	if event.Link == "" {
+		event.Link = n.targetLink(event.URLID)
+	}
+
+	sender, err := n.senderFor(channel.Type)
+	if err == nil {
+		err = n.sendWithRetry(sender, channel, event, &delivery)
+	}
+
+	delivery.Success = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+		log.Printf("[NOTIFY] %s to %s failed: %v\n", delivery.Event, channel.Name, err)
+	} else {
+		log.Printf("[NOTIFY] %s to %s delivered\n", delivery.Event, channel.Name)
+	}
+	if err := n.store.AddDelivery(delivery); err != nil {
+		log.Printf("[NOTIFY] Cannot record delivery: %v\n", err)
+	}
+	return delivery
+}
+
+// targetLink membuat link ke halaman target di dashboard berdasarkan setting
+// public_url ("" jika belum diatur)
+func (n *Notifier) targetLink(urlID int) string {
+	base, err := n.store.GetSetting("public_url", "")
+	if err != nil || base == "" {
+		return ""
+	}
+	base = strings.TrimRight(base, "/")
+	if urlID == 0 {
+		return base + "/"
+	}
+	return base + "/?url_id=" + strconv.Itoa(urlID)
+}
+
+// sendWithRetry mengirim alert hingga MaxAttempts kali dengan jeda yang
+// berlipat dua setiap percobaan, berhenti pada percobaan yang berhasil atau
+// kegagalan permanen
+func (n *Notifier) sendWithRetry(sender Sender, channel models.NotificationChannel, event models.AlertEvent, delivery *models.NotificationDelivery) error {
+	delay := n.RetryDelay
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		statusCode, err := sender.Send(ctx, channel, event)
+		cancel()
+		delivery.Attempts = attempt
+		delivery.StatusCode = statusCode
+
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) || attempt >= n.MaxAttempts {
+			return err
+		}
+		log.Printf("[NOTIFY] %s to %s attempt %d failed, retrying: %v\n", delivery.Event, channel.Name, attempt, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
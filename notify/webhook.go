@@ -0,0 +1,141 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"test/models"
+	"time"
+)
+
+func init() {
+	Register(models.ChannelWebhook, webhookSender{})
+}
+
+// Header yang dikirim bersama payload webhook. Signature adalah
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	HeaderEvent     = "X-Fprobe-Event"
+	HeaderTimestamp = "X-Fprobe-Timestamp"
+	HeaderSignature = "X-Fprobe-Signature"
+)
+
+// webhookPayload adalah body JSON yang dikirim ke webhook
+type webhookPayload struct {
+	Event      string    `json:"event"`
+	Target     target    `json:"target"`
+	OldState   string    `json:"old_state"`
+	NewState   string    `json:"new_state"`
+	StatusCode int       `json:"status_code"`
+	Error      string    `json:"error"`
+	ErrorClass string    `json:"error_class"`
+	LatencyMs  int64     `json:"latency_ms"`
+	Level      string    `json:"level,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Link       string    `json:"link,omitempty"`
+}
+
+type target struct {
+	ID        int    `json:"id"`
+	URL       string `json:"url"`
+	ProbeType string `json:"probe_type"`
+}
+
+func newWebhookPayload(event models.AlertEvent) webhookPayload {
+	return webhookPayload{
+		Event: event.Name(),
+		Target: target{
+			ID:        event.URLID,
+			URL:       event.URL,
+			ProbeType: event.ProbeType,
+		},
+		OldState:   event.OldState,
+		NewState:   event.NewState,
+		StatusCode: event.StatusCode,
+		Error:      event.Error,
+		ErrorClass: string(event.ErrorClass),
+		LatencyMs:  event.LatencyMs,
+		Level:      event.Level,
+		Timestamp:  event.Timestamp,
+		Link:       event.Link,
+	}
+}
+
+// webhookSender mengirim alert sebagai JSON ke URL channel
+type webhookSender struct{}
+
+func (webhookSender) Validate(channel models.NotificationChannel) error {
+	return validateHTTPURL(channel.URL)
+}
+
+func (webhookSender) Send(ctx context.Context, channel models.NotificationChannel, event models.AlertEvent) (int, error) {
+	body, err := json.Marshal(newWebhookPayload(event))
+	if err != nil {
+		return 0, Permanent(err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		HeaderEvent:     event.Name(),
+		HeaderTimestamp: timestamp,
+	}
+	if channel.Secret != "" {
+		headers[HeaderSignature] = Sign(channel.Secret, timestamp, body)
+	}
+	return postJSON(ctx, channel.URL, body, headers)
+}
+
+// Sign menghitung signature payload webhook, untuk diverifikasi oleh
+// penerima dengan secret yang sama
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateHTTPURL memeriksa URL tujuan channel berbasis HTTP
+func validateHTTPURL(url string) error {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("URL webhook harus diawali http:// atau https://: %q", url)
+	}
+	return nil
+}
+
+// postJSON mengirim body JSON ke url. Status 2xx dianggap berhasil; 4xx
+// (kecuali 408 dan 429) dianggap kegagalan permanen sehingga tidak diulang.
+func postJSON(ctx context.Context, url string, body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fprobe-notifier")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return resp.StatusCode, fmt.Errorf("webhook merespons %s", resp.Status)
+	default:
+		return resp.StatusCode, Permanent(fmt.Errorf("webhook merespons %s", resp.Status))
+	}
+}
@@ -0,0 +1,194 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"test/database"
+	"test/models"
+	"testing"
+	"time"
+)
+
+// newTestNotifier membuat Notifier dengan database sementara dan jeda retry
+// yang sangat singkat
+func newTestNotifier(t *testing.T) *Notifier {
+	t.Helper()
+	store := database.NewStore(filepath.Join(t.TempDir(), "probe.db"))
+	t.Cleanup(func() { store.Db.Close() })
+
+	n := New(store)
+	n.RetryDelay = time.Millisecond
+	return n
+}
+
+// webhookRequest adalah satu request yang diterima server webhook uji
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// startWebhookServer menjalankan server webhook uji yang menjawab dengan
+// status dari codes secara berurutan (status terakhir dipakai seterusnya)
+func startWebhookServer(t *testing.T, codes ...int) (*httptest.Server, func() []webhookRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, webhookRequest{header: r.Header.Clone(), body: body})
+		code := codes[min(len(requests), len(codes))-1]
+		mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []webhookRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookRequest(nil), requests...)
+	}
+}
+
+func testEvent() models.AlertEvent {
+	return models.AlertEvent{
+		URLID:      7,
+		URL:        "https://api.example.com/health",
+		ProbeType:  models.ProbeHTTP,
+		OldState:   models.StateUp,
+		NewState:   models.StateDown,
+		StatusCode: 503,
+		ErrorClass: models.ErrorClassStatus,
+		Error:      "Status 503 tidak sesuai aturan 200",
+		LatencyMs:  120,
+		Timestamp:  time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestWebhookPayloadAndSignature(t *testing.T) {
+	n := newTestNotifier(t)
+	if err := n.store.SetSetting("public_url", "https://fprobe.example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	server, requests := startWebhookServer(t, http.StatusOK)
+
+	channel := models.NotificationChannel{ID: 1, Name: "ops", Type: models.ChannelWebhook, URL: server.URL, Secret: "s3cret"}
+	delivery := n.Deliver(channel, testEvent())
+	if !delivery.Success || delivery.Attempts != 1 || delivery.StatusCode != http.StatusOK {
+		t.Fatalf("delivery = %+v, want success in 1 attempt", delivery)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1", len(got))
+	}
+	req := got[0]
+
+	var payload map[string]any
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	want := map[string]any{
+		"event":       "target.down",
+		"old_state":   "up",
+		"new_state":   "down",
+		"status_code": 503.0,
+		"error":       "Status 503 tidak sesuai aturan 200",
+		"error_class": "status",
+		"latency_ms":  120.0,
+		"timestamp":   "2026-01-02T15:04:05Z",
+		"link":        "https://fprobe.example.com/?url_id=7",
+	}
+	for key, value := range want {
+		if payload[key] != value {
+			t.Errorf("payload[%q] = %v, want %v", key, payload[key], value)
+		}
+	}
+	target, _ := payload["target"].(map[string]any)
+	if target["id"] != 7.0 || target["url"] != "https://api.example.com/health" || target["probe_type"] != "http" {
+		t.Errorf("payload target = %v", target)
+	}
+	if _, ok := payload["level"]; ok {
+		t.Errorf("level must be omitted for down events")
+	}
+
+	if e := req.header.Get(HeaderEvent); e != "target.down" {
+		t.Errorf("%s = %q, want target.down", HeaderEvent, e)
+	}
+	timestamp := req.header.Get(HeaderTimestamp)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(timestamp + "." + string(req.body)))
+	wantSig := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if sig := req.header.Get(HeaderSignature); sig != wantSig {
+		t.Errorf("%s = %q, want %q", HeaderSignature, sig, wantSig)
+	}
+	if sig := Sign("s3cret", timestamp, req.body); sig != wantSig {
+		t.Errorf("Sign = %q, want %q", sig, wantSig)
+	}
+
+	deliveries, err := n.store.GetDeliveries(10)
+	if err != nil || len(deliveries) != 1 || !deliveries[0].Success {
+		t.Errorf("recorded deliveries = %+v, %v", deliveries, err)
+	}
+}
+
+func TestWebhookWithoutSecretIsUnsigned(t *testing.T) {
+	n := newTestNotifier(t)
+	server, requests := startWebhookServer(t, http.StatusNoContent)
+
+	channel := models.NotificationChannel{Name: "ops", Type: models.ChannelWebhook, URL: server.URL}
+	if delivery := n.Deliver(channel, testEvent()); !delivery.Success {
+		t.Fatalf("delivery failed: %s", delivery.Error)
+	}
+	if sig := requests()[0].header.Get(HeaderSignature); sig != "" {
+		t.Errorf("%s = %q, want no signature", HeaderSignature, sig)
+	}
+}
+
+func TestWebhookRetriesServerError(t *testing.T) {
+	n := newTestNotifier(t)
+	server, requests := startWebhookServer(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	channel := models.NotificationChannel{Name: "ops", Type: models.ChannelWebhook, URL: server.URL}
+	delivery := n.Deliver(channel, testEvent())
+	if !delivery.Success || delivery.Attempts != 2 || delivery.StatusCode != http.StatusOK {
+		t.Errorf("delivery = %+v, want success on attempt 2", delivery)
+	}
+	if len(requests()) != 2 {
+		t.Errorf("got %d requests, want 2", len(requests()))
+	}
+}
+
+func TestWebhookGivesUpAfterMaxAttempts(t *testing.T) {
+	n := newTestNotifier(t)
+	server, requests := startWebhookServer(t, http.StatusBadGateway)
+
+	channel := models.NotificationChannel{Name: "ops", Type: models.ChannelWebhook, URL: server.URL}
+	delivery := n.Deliver(channel, testEvent())
+	if delivery.Success || delivery.Attempts != DefaultMaxAttempts {
+		t.Errorf("delivery = %+v, want failure after %d attempts", delivery, DefaultMaxAttempts)
+	}
+	if len(requests()) != DefaultMaxAttempts {
+		t.Errorf("got %d requests, want %d", len(requests()), DefaultMaxAttempts)
+	}
+}
+
+func TestWebhookDoesNotRetryClientError(t *testing.T) {
+	n := newTestNotifier(t)
+	server, requests := startWebhookServer(t, http.StatusBadRequest, http.StatusOK)
+
+	channel := models.NotificationChannel{Name: "ops", Type: models.ChannelWebhook, URL: server.URL}
+	delivery := n.Deliver(channel, testEvent())
+	if delivery.Success || delivery.Attempts != 1 || delivery.StatusCode != http.StatusBadRequest {
+		t.Errorf("delivery = %+v, want permanent failure on attempt 1", delivery)
+	}
+	if len(requests()) != 1 {
+		t.Errorf("got %d requests, want 1", len(requests()))
+	}
+}
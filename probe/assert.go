@@ -0,0 +1,100 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"test/models"
+)
+
+// validateAssertions memastikan semua assertion bisa dievaluasi (regex
+// dan ekspresi JSON valid).
+func validateAssertions(assertions []models.Assertion) error {
+	for _, a := range assertions {
+		switch a.Type {
+		case models.AssertRegex:
+			if _, err := regexp.Compile(a.Value); err != nil {
+				return fmt.Errorf("regex tidak valid pada %q: %w", a, err)
+			}
+		case models.AssertJSON:
+			if _, err := parseJSONExpr(a.Value); err != nil {
+				return fmt.Errorf("ekspresi JSON tidak valid pada %q: %w", a, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAssertions menjalankan semua assertion terhadap body response.
+// truncated menandai body yang dipotong di maxBodyBytes; assertion yang gagal
+// pada body seperti itu diberi keterangan agar tidak terlihat seperti isi
+// response yang salah. Mengembalikan hasil per assertion dan deskripsi
+// assertion pertama yang gagal ("" jika semua lolos).
+func checkAssertions(assertions []models.Assertion, body []byte, truncated bool) ([]models.AssertionResult, string) {
+	results := make([]models.AssertionResult, 0, len(assertions))
+	failed := ""
+
+	var doc any
+	var docErr error
+	docParsed := false
+
+	for _, a := range assertions {
+		var passed bool
+		var message string
+
+		if a.Type == models.AssertJSON {
+			if !docParsed {
+				docErr = json.Unmarshal(body, &doc)
+				docParsed = true
+			}
+			if docErr != nil {
+				passed, message = false, "body bukan JSON valid"
+			} else {
+				passed, message = evalJSONAssertion(a.Value, doc)
+			}
+		} else {
+			passed = evalAssertion(a, body)
+		}
+		if !passed && truncated {
+			message = fmt.Sprintf("body lebih dari %d MB dan terpotong", maxBodyBytes>>20)
+		}
+
+		results = append(results, models.AssertionResult{
+			Assertion: a.String(),
+			Passed:    passed,
+			Message:   message,
+		})
+		if !passed && failed == "" {
+			failed = fmt.Sprintf("assertion gagal: %s", a)
+			if message != "" {
+				failed += " (" + message + ")"
+			}
+		}
+	}
+	return results, failed
+}
+
+func evalAssertion(a models.Assertion, body []byte) bool {
+	switch a.Type {
+	case models.AssertContains:
+		return bytes.Contains(body, []byte(a.Value))
+	case models.AssertNotContains:
+		return !bytes.Contains(body, []byte(a.Value))
+	case models.AssertRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return false
+		}
+		return re.Match(body)
+	}
+	return false
+}
+
+func evalJSONAssertion(exprText string, doc any) (bool, string) {
+	expr, err := parseJSONExpr(exprText)
+	if err != nil {
+		return false, err.Error()
+	}
+	return expr.eval(doc)
+}
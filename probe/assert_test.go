@@ -0,0 +1,58 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"test/models"
+	"testing"
+)
+
+func TestAssertionsOnTruncatedBody(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		size := maxBodyBytes - 100
+		if r.URL.Path == "/large" {
+			size = maxBodyBytes + 100
+		}
+		fmt.Fprintf(w, `{"status":"ok","pad":"%s","tail":"END"}`, strings.Repeat("x", size))
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		assertions  string
+		wantUp      bool
+		wantMessage string
+	}{
+		{"JSON di bawah batas", "/small", `json: $.status == "ok"`, true, ""},
+		{"JSON terpotong", "/large", `json: $.status == "ok"`, false, "body lebih dari 1 MB dan terpotong"},
+		{"contains setelah batas", "/large", `contains: END`, false, "body lebih dari 1 MB dan terpotong"},
+		{"contains sebelum batas", "/large", `contains: "status"`, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertions, err := models.ParseAssertions(tt.assertions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Run(context.Background(), models.TargetURL{
+				URL:        server.URL + tt.path,
+				Method:     http.MethodGet,
+				Assertions: assertions,
+			})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if result.Up != tt.wantUp {
+				t.Fatalf("Up = %v, want %v (error: %s)", result.Up, tt.wantUp, result.ErrorMessage)
+			}
+			if got := result.Assertions[0].Message; !strings.Contains(got, tt.wantMessage) {
+				t.Errorf("Message = %q, want %q", got, tt.wantMessage)
+			}
+			if !tt.wantUp && result.ErrorClass != models.ErrorClassAssertion {
+				t.Errorf("ErrorClass = %q, want %q", result.ErrorClass, models.ErrorClassAssertion)
+			}
+		})
+	}
+}
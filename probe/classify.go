@@ -0,0 +1,91 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"test/models"
+)
+
+// classify mengisi ErrorClass dan ErrorMessage untuk probe yang gagal
+func classify(target models.TargetURL, result *ProbeResult) {
+	switch {
+	case result.Up:
+		return
+	case result.NetworkErr:
+		result.ErrorClass = classifyNetworkError(result.Err)
+		result.ErrorMessage = "Network error / timeout"
+		if result.Err != nil {
+			result.ErrorMessage = result.Err.Error()
+		}
+	case result.FailedAssertion != "":
+		// Prober boleh mengisi kelas yang lebih spesifik (mis. NODATA pada DNS)
+		if result.ErrorClass == "" {
+			result.ErrorClass = models.ErrorClassAssertion
+		}
+		result.ErrorMessage = result.FailedAssertion
+	default:
+		result.ErrorClass = models.ErrorClassStatus
+		result.ErrorMessage = target.StatusMismatch(result.StatusCode)
+	}
+}
+
+// classifyNetworkError menentukan penyebab kegagalan jaringan dari error
+// mentah. Pemeriksaan teks dipakai sebagai cadangan untuk error yang tidak
+// dibungkus dengan tipe (mis. kode errno di Windows).
+func classifyNetworkError(err error) models.ErrorClass {
+	if err == nil {
+		return models.ErrorClassNetwork
+	}
+	msg := strings.ToLower(err.Error())
+
+	var (
+		dnsErr       *net.DNSError
+		verifyErr    *tls.CertificateVerificationError
+		unknownCAErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		alertErr     tls.AlertError
+		recordErr    tls.RecordHeaderError
+		netErr       net.Error
+	)
+	switch {
+	case errors.Is(err, errTooManyRedirects):
+		return models.ErrorTooManyRedirects
+	case errors.As(err, &verifyErr), errors.As(err, &unknownCAErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return models.ErrorCertInvalid
+	case errors.As(err, &alertErr), errors.As(err, &recordErr):
+		return models.ErrorTLSHandshake
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsNotFound {
+			return models.ErrorDNSNXDomain
+		}
+		if dnsErr.IsTimeout {
+			return models.ErrorTimeout
+		}
+		return models.ErrorDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return models.ErrorTimeout
+	case errors.Is(err, syscall.ECONNREFUSED),
+		strings.Contains(msg, "connection refused"), strings.Contains(msg, "actively refused"):
+		return models.ErrorConnRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		strings.Contains(msg, "connection reset"), strings.Contains(msg, "forcibly closed"),
+		strings.Contains(msg, "server closed idle connection"):
+		return models.ErrorConnReset
+	case strings.Contains(msg, "tls: "), strings.Contains(msg, "http response to https client"):
+		// net/http tidak membungkus RecordHeaderError saat server menjawab
+		// HTTP biasa pada koneksi HTTPS
+		return models.ErrorTLSHandshake
+	}
+	return models.ErrorClassNetwork
+}
@@ -0,0 +1,149 @@
+package probe
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"test/models"
+	"testing"
+	"time"
+)
+
+// closedAddr mengembalikan alamat 127.0.0.1 yang tidak sedang dipakai
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// resetServer menerima koneksi lalu langsung menutupnya tanpa response
+func resetServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClassifyProbeFailures(t *testing.T) {
+	ok := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"degraded"}`))
+	})
+	failing := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	slow := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	var loop *httptest.Server
+	loop = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, loop.URL+"/again", http.StatusFound)
+	})
+	tlsServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0) // handshake yang ditolak klien memang disengaja
+	tlsServer.StartTLS()
+	t.Cleanup(tlsServer.Close)
+
+	assertions, err := models.ParseAssertions(`json: $.status == "ok"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		target  models.TargetURL
+		timeout time.Duration
+		want    models.ErrorClass
+	}{
+		{"connection refused", models.TargetURL{URL: "http://" + closedAddr(t)}, 0, models.ErrorConnRefused},
+		{"tcp connection refused", models.TargetURL{ProbeType: models.ProbeTCP, URL: closedAddr(t)}, 0, models.ErrorConnRefused},
+		{"connection reset", models.TargetURL{URL: "http://" + resetServer(t)}, 0, models.ErrorConnReset},
+		{"timeout", models.TargetURL{URL: slow.URL}, 100 * time.Millisecond, models.ErrorTimeout},
+		{"unknown CA", models.TargetURL{URL: tlsServer.URL}, 0, models.ErrorCertInvalid},
+		{"TLS ke server HTTP", models.TargetURL{URL: strings.Replace(ok.URL, "http://", "https://", 1)}, 0, models.ErrorTLSHandshake},
+		{"redirect loop", models.TargetURL{URL: loop.URL}, 0, models.ErrorTooManyRedirects},
+		{"status", models.TargetURL{URL: failing.URL}, 0, models.ErrorClassStatus},
+		{"assertion", models.TargetURL{URL: ok.URL, Assertions: assertions}, 0, models.ErrorClassAssertion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			if tt.target.Method == "" {
+				tt.target.Method = http.MethodGet
+			}
+			result, err := Run(ctx, tt.target)
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if result.Up {
+				t.Fatal("probe succeeded, want failure")
+			}
+			if result.ErrorClass != tt.want {
+				t.Errorf("ErrorClass = %q, want %q (error: %s)", result.ErrorClass, tt.want, result.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestClassifyDNSErrors(t *testing.T) {
+	resolver := newResolver(startDNSServer(t, testZone{"example.test.": {}}))
+
+	_, err := resolver.LookupHost(context.Background(), "missing.test")
+	if got := classifyNetworkError(err); got != models.ErrorDNSNXDomain {
+		t.Errorf("NXDOMAIN: classifyNetworkError(%v) = %q, want %q", err, got, models.ErrorDNSNXDomain)
+	}
+
+	// Resolver yang tidak menjawab sama sekali
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = newResolver(silent.LocalAddr().String()).LookupHost(ctx, "example.test")
+	if got := classifyNetworkError(err); got != models.ErrorTimeout {
+		t.Errorf("no answer: classifyNetworkError(%v) = %q, want %q", err, got, models.ErrorTimeout)
+	}
+}
+
+func TestClassifyNetworkErrorNil(t *testing.T) {
+	if got := classifyNetworkError(nil); got != models.ErrorClassNetwork {
+		t.Errorf("classifyNetworkError(nil) = %q, want %q", got, models.ErrorClassNetwork)
+	}
+}
@@ -0,0 +1,282 @@
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"test/models"
+	"time"
+)
+
+func init() {
+	Register(models.ProbeDNS, func() Prober { return &dnsProber{} })
+}
+
+// dnsProber me-resolve nama domain target (TargetURL.URL) untuk record
+// DNSRecordType lewat DNSResolver (host:port, kosong = resolver sistem) dan
+// memeriksa bahwa setiap nilai DNSExpected ada di hasil resolusi.
+type dnsProber struct {
+	host       string
+	recordType string
+	resolver   *dnsResolver
+	expected   string
+}
+
+// dnsQueryTypes adalah kode QTYPE (RFC 1035) untuk jenis record yang didukung
+var dnsQueryTypes = map[string]uint16{
+	models.DNSRecordA:     1,
+	models.DNSRecordAAAA:  28,
+	models.DNSRecordCNAME: 5,
+	models.DNSRecordMX:    15,
+	models.DNSRecordTXT:   16,
+}
+
+func (p *dnsProber) Configure(target models.TargetURL) error {
+	if target.URL == "" || strings.ContainsAny(target.URL, "/: ") {
+		return fmt.Errorf("target DNS harus berupa nama domain: %q", target.URL)
+	}
+	recordType := strings.ToUpper(target.DNSRecordType)
+	if recordType == "" {
+		recordType = models.DNSRecordA
+	}
+	if !models.ValidDNSRecordTypes[recordType] {
+		return fmt.Errorf("jenis record DNS tidak didukung: %q", target.DNSRecordType)
+	}
+
+	p.host = target.URL
+	p.recordType = recordType
+	p.resolver = newResolver(target.DNSResolver)
+	p.expected = target.DNSExpected
+	return nil
+}
+
+func (p *dnsProber) Probe(ctx context.Context) ProbeResult {
+	startTime := time.Now()
+	records, err := lookupRecords(ctx, p.resolver, p.recordType, p.host)
+	milliseconds := time.Since(startTime).Milliseconds()
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound && p.resolver.isNoData(ctx, p.host, dnsQueryTypes[p.recordType]) {
+		// NODATA: nama ada tapi tidak punya record jenis ini
+		records, err = nil, nil
+	}
+
+	if err != nil {
+		return ProbeResult{
+			StatusCode: 0,
+			LatencyMs:  milliseconds,
+			NetworkErr: true,
+			Err:        err,
+			Timings:    models.PhaseTimings{DNSMs: milliseconds},
+		}
+	}
+
+	result := ProbeResult{
+		LatencyMs: milliseconds,
+		Up:        true,
+		Timings:   models.PhaseTimings{DNSMs: milliseconds},
+	}
+	if len(records) == 0 {
+		result.Up = false
+		result.FailedAssertion = fmt.Sprintf("tidak ada record %s", p.recordType)
+		result.ErrorClass = models.ErrorDNSNoRecords
+		return result
+	}
+
+	if missing := missingRecords(p.recordType, p.expected, records); len(missing) > 0 {
+		result.Up = false
+		result.FailedAssertion = fmt.Sprintf("record %s tidak mengandung %s (diterima: %s)",
+			p.recordType, strings.Join(missing, ", "), strings.Join(records, ", "))
+	}
+	return result
+}
+
+// dnsResolver adalah resolver Go yang mencatat server DNS terakhir yang
+// dihubungi. Resolver Go melaporkan NXDOMAIN dan NODATA dengan error yang
+// sama (IsNotFound), jadi server itu ditanya ulang untuk membedakannya.
+type dnsResolver struct {
+	*net.Resolver
+
+	mu     sync.Mutex
+	server string
+}
+
+// newResolver membuat resolver yang memakai server DNS tertentu. Jika
+// address kosong, server dari konfigurasi sistem (/etc/resolv.conf) yang
+// dipakai.
+func newResolver(address string) *dnsResolver {
+	if address != "" {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, "53")
+		}
+	}
+	r := &dnsResolver{}
+	r.Resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, server string) (net.Conn, error) {
+			if address != "" {
+				server = address
+			}
+			r.mu.Lock()
+			r.server = server
+			r.mu.Unlock()
+			d := net.Dialer{}
+			return d.DialContext(ctx, network, server)
+		},
+	}
+	return r
+}
+
+// isNoData bernilai true jika server DNS terakhir menjawab NOERROR untuk
+// host dan qtype, artinya nama ada tapi tidak punya record jenis itu
+func (r *dnsResolver) isNoData(ctx context.Context, host string, qtype uint16) bool {
+	r.mu.Lock()
+	server := r.server
+	r.mu.Unlock()
+	if server == "" || qtype == 0 {
+		return false
+	}
+	rcode, err := queryRcode(ctx, server, host, qtype)
+	return err == nil && rcode == 0
+}
+
+// queryRcode mengirim satu query DNS lewat UDP dan mengembalikan RCODE
+// response (0 = NOERROR, 3 = NXDOMAIN)
+func queryRcode(ctx context.Context, server, host string, qtype uint16) (int, error) {
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	id := uint16(rand.Uint32())
+	query := binary.BigEndian.AppendUint16(nil, id)
+	query = append(query, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0) // RD, satu pertanyaan
+	query, err = appendDNSName(query, host)
+	if err != nil {
+		return 0, err
+	}
+	query = binary.BigEndian.AppendUint16(query, qtype)
+	query = binary.BigEndian.AppendUint16(query, 1) // class IN
+	if _, err := conn.Write(query); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 512)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return 0, err
+		}
+		// Abaikan paket yang bukan response untuk query ini
+		if n >= 12 && binary.BigEndian.Uint16(buf) == id && buf[2]&0x80 != 0 {
+			return int(buf[3] & 0x0F), nil
+		}
+	}
+}
+
+// appendDNSName menambahkan nama domain dalam format label DNS ke b
+func appendDNSName(b []byte, name string) ([]byte, error) {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return nil, fmt.Errorf("nama domain tidak valid: %q", name)
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0), nil
+}
+
+// lookupRecords mengembalikan hasil resolusi dalam bentuk teks. Nama host
+// dinormalisasi (huruf kecil, tanpa titik di akhir); nilai TXT tidak diubah.
+func lookupRecords(ctx context.Context, resolver *dnsResolver, recordType, host string) ([]string, error) {
+	var records []string
+	switch recordType {
+	case models.DNSRecordA, models.DNSRecordAAAA:
+		network := "ip4"
+		if recordType == models.DNSRecordAAAA {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case models.DNSRecordCNAME:
+		cname, err := resolver.LookupCNAME(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, normalizeDNSName(cname))
+	case models.DNSRecordMX:
+		mxs, err := resolver.LookupMX(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, normalizeDNSName(mx.Host))
+		}
+	case models.DNSRecordTXT:
+		txts, err := resolver.LookupTXT(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, txts...)
+	default:
+		return nil, fmt.Errorf("jenis record DNS tidak didukung: %s", recordType)
+	}
+	sort.Strings(records)
+	return records, nil
+}
+
+// missingRecords mengembalikan nilai expected yang tidak ada di hasil
+// resolusi. Nama host dibandingkan tanpa huruf besar/titik akhir, sedangkan
+// nilai TXT harus sama persis.
+func missingRecords(recordType, expected string, records []string) []string {
+	normalize := normalizeDNSName
+	if recordType == models.DNSRecordTXT {
+		normalize = strings.TrimSpace
+	}
+	found := make(map[string]bool, len(records))
+	for _, r := range records {
+		found[normalize(r)] = true
+	}
+	var missing []string
+	for _, want := range splitExpected(recordType, expected) {
+		want = strings.TrimSpace(want)
+		if want == "" {
+			continue
+		}
+		if !found[normalize(want)] {
+			missing = append(missing, want)
+		}
+	}
+	return missing
+}
+
+// splitExpected memisah DNSExpected menjadi nilai-nilai. Nilai TXT dipisah
+// per baris karena TXT seperti SPF dan DMARC bisa berisi koma; jenis lain
+// boleh dipisah koma atau baris.
+func splitExpected(recordType, expected string) []string {
+	separator := func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }
+	if recordType == models.DNSRecordTXT {
+		separator = func(r rune) bool { return r == '\n' || r == '\r' }
+	}
+	return strings.FieldsFunc(expected, separator)
+}
+
+func normalizeDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
@@ -0,0 +1,193 @@
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"test/models"
+	"testing"
+)
+
+// Jenis record DNS (RFC 1035)
+const (
+	dnsTypeA   = 1
+	dnsTypeMX  = 15
+	dnsTypeTXT = 16
+)
+
+// testZone adalah isi server DNS uji: nama (huruf kecil, diakhiri titik) ->
+// jenis record -> rdata setiap jawaban
+type testZone map[string]map[uint16][][]byte
+
+// startDNSServer menjalankan server DNS UDP di 127.0.0.1 yang menjawab dari
+// zone. Nama yang tidak ada di zone dijawab NXDOMAIN.
+func startDNSServer(t *testing.T, zone testZone) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp := answerDNS(zone, buf[:n]); resp != nil {
+				conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// answerDNS menyusun response untuk satu query
+func answerDNS(zone testZone, query []byte) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+	// Baca nama pertanyaan label demi label
+	var labels []string
+	off := 12
+	for off < len(query) && query[off] != 0 {
+		l := int(query[off])
+		if off+1+l > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[off+1:off+1+l]))
+		off += 1 + l
+	}
+	off++ // label kosong penutup
+	if off+4 > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[off:])
+	question := query[12 : off+4]
+	name := strings.ToLower(strings.Join(labels, ".")) + "."
+
+	records, exists := zone[name]
+	flags := uint16(0x8180) // QR, RD, RA
+	if !exists {
+		flags |= 3 // NXDOMAIN
+	}
+	answers := records[qtype]
+
+	resp := make([]byte, 12, 512)
+	copy(resp, query[:2])
+	binary.BigEndian.PutUint16(resp[2:], flags)
+	binary.BigEndian.PutUint16(resp[4:], 1)
+	binary.BigEndian.PutUint16(resp[6:], uint16(len(answers)))
+	resp = append(resp, question...)
+	for _, rdata := range answers {
+		resp = append(resp, 0xC0, 12) // pointer ke nama pertanyaan
+		resp = binary.BigEndian.AppendUint16(resp, qtype)
+		resp = binary.BigEndian.AppendUint16(resp, 1) // class IN
+		resp = binary.BigEndian.AppendUint32(resp, 60)
+		resp = binary.BigEndian.AppendUint16(resp, uint16(len(rdata)))
+		resp = append(resp, rdata...)
+	}
+	return resp
+}
+
+func encodeDNSName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+func rdataA(ip string) []byte {
+	return net.ParseIP(ip).To4()
+}
+
+func rdataMX(pref uint16, host string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, pref), encodeDNSName(host)...)
+}
+
+func rdataTXT(text string) []byte {
+	return append([]byte{byte(len(text))}, text...)
+}
+
+func TestDNSProbe(t *testing.T) {
+	resolver := startDNSServer(t, testZone{
+		"example.test.": {
+			dnsTypeA:   {rdataA("203.0.113.10"), rdataA("203.0.113.11")},
+			dnsTypeMX:  {rdataMX(10, "mx1.example.test"), rdataMX(20, "mx2.example.test")},
+			dnsTypeTXT: {rdataTXT("v=spf1 -all"), rdataTXT("Verify=AbC123")},
+		},
+		"_dmarc.example.test.": {
+			dnsTypeTXT: {rdataTXT("v=DMARC1; p=reject; rua=mailto:a@example.test,mailto:b@example.test")},
+		},
+		"empty.test.": {},
+	})
+
+	tests := []struct {
+		name       string
+		host       string
+		recordType string
+		expected   string
+		wantUp     bool
+		wantClass  models.ErrorClass
+	}{
+		{"A match", "example.test", models.DNSRecordA, "203.0.113.10, 203.0.113.11", true, ""},
+		{"A tanpa expected", "example.test", models.DNSRecordA, "", true, ""},
+		{"A mismatch", "example.test", models.DNSRecordA, "203.0.113.99", false, models.ErrorClassAssertion},
+		{"MX match tanpa memperhatikan huruf besar", "example.test", models.DNSRecordMX, "MX1.example.test.", true, ""},
+		{"MX mismatch", "example.test", models.DNSRecordMX, "mx3.example.test", false, models.ErrorClassAssertion},
+		{"TXT match", "example.test", models.DNSRecordTXT, "Verify=AbC123", true, ""},
+		{"TXT peka huruf besar", "example.test", models.DNSRecordTXT, "verify=abc123", false, models.ErrorClassAssertion},
+		{"TXT berisi koma", "_dmarc.example.test", models.DNSRecordTXT,
+			"v=DMARC1; p=reject; rua=mailto:a@example.test,mailto:b@example.test", true, ""},
+		{"TXT per baris", "example.test", models.DNSRecordTXT, "v=spf1 -all\nVerify=AbC123", true, ""},
+		{"record tidak ada (NODATA)", "empty.test", models.DNSRecordMX, "", false, models.ErrorDNSNoRecords},
+		{"NXDOMAIN", "missing.test", models.DNSRecordA, "", false, models.ErrorDNSNXDomain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(context.Background(), models.TargetURL{
+				ProbeType:     models.ProbeDNS,
+				URL:           tt.host,
+				DNSRecordType: tt.recordType,
+				DNSResolver:   resolver,
+				DNSExpected:   tt.expected,
+			})
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			if result.Up != tt.wantUp {
+				t.Fatalf("Up = %v, want %v (error: %s)", result.Up, tt.wantUp, result.ErrorMessage)
+			}
+			if result.ErrorClass != tt.wantClass {
+				t.Errorf("ErrorClass = %q, want %q (error: %s)", result.ErrorClass, tt.wantClass, result.ErrorMessage)
+			}
+		})
+	}
+}
+
+func TestMissingRecords(t *testing.T) {
+	tests := []struct {
+		recordType string
+		expected   string
+		records    []string
+		want       []string
+	}{
+		{models.DNSRecordCNAME, "Target.Example.com.", []string{"target.example.com"}, nil},
+		{models.DNSRecordTXT, "token=ABC", []string{"token=abc"}, []string{"token=ABC"}},
+		{models.DNSRecordTXT, " token=ABC ", []string{"token=ABC"}, nil},
+		{models.DNSRecordA, "10.0.0.1, 10.0.0.2", []string{"10.0.0.1"}, []string{"10.0.0.2"}},
+		{models.DNSRecordA, "10.0.0.1\n10.0.0.2", []string{"10.0.0.1", "10.0.0.2"}, nil},
+		{models.DNSRecordTXT, "a=1,b=2\r\nc=3", []string{"a=1,b=2"}, []string{"c=3"}},
+	}
+	for _, tt := range tests {
+		got := missingRecords(tt.recordType, tt.expected, tt.records)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("missingRecords(%s, %q, %v) = %v, want %v", tt.recordType, tt.expected, tt.records, got, tt.want)
+		}
+	}
+}
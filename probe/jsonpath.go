@@ -0,0 +1,220 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonExpr adalah assertion JSON yang sudah di-parse, contoh:
+//
+//	$.status                 field harus ada (dan bukan null)
+//	$.db == "up"             perbandingan dengan literal JSON
+//	$.checks[0].latency < 200
+//	$.items.length >= 3      panjang array, object atau string
+type jsonExpr struct {
+	path    []pathSegment
+	op      string
+	literal any
+}
+
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// operator diurutkan agar operator dua karakter dicek lebih dulu
+var jsonOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// parseJSONExpr membaca ekspresi "path [operator literal]"
+func parseJSONExpr(text string) (*jsonExpr, error) {
+	text = strings.TrimSpace(text)
+	pathText, op, literalText := splitJSONExpr(text)
+
+	path, err := parseJSONPath(strings.TrimSpace(pathText))
+	if err != nil {
+		return nil, err
+	}
+	expr := &jsonExpr{path: path, op: op}
+	if op == "" {
+		return expr, nil
+	}
+
+	literalText = strings.TrimSpace(literalText)
+	if err := json.Unmarshal([]byte(literalText), &expr.literal); err != nil {
+		return nil, fmt.Errorf("literal tidak valid %q (gunakan format JSON, string pakai tanda kutip)", literalText)
+	}
+	if op != "==" && op != "!=" {
+		if _, ok := expr.literal.(float64); !ok {
+			return nil, fmt.Errorf("operator %s hanya untuk angka", op)
+		}
+	}
+	return expr, nil
+}
+
+// splitJSONExpr memisahkan path, operator dan literal. Operator di dalam
+// tanda kutip diabaikan.
+func splitJSONExpr(text string) (string, string, string) {
+	inQuote := false
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '"' && (i == 0 || text[i-1] != '\\'):
+			inQuote = !inQuote
+		case !inQuote:
+			for _, op := range jsonOperators {
+				if strings.HasPrefix(text[i:], op) {
+					return text[:i], op, text[i+len(op):]
+				}
+			}
+		}
+	}
+	return text, "", ""
+}
+
+// parseJSONPath membaca path seperti $.a.b[0]["c d"]
+func parseJSONPath(text string) ([]pathSegment, error) {
+	if !strings.HasPrefix(text, "$") {
+		return nil, fmt.Errorf("path JSON harus diawali '$': %q", text)
+	}
+	var segments []pathSegment
+	rest := text[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" {
+				return nil, fmt.Errorf("path JSON tidak valid: %q", text)
+			}
+			segments = append(segments, pathSegment{key: key})
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("path JSON tidak valid (kurang ']'): %q", text)
+			}
+			inner := rest[1:end]
+			if idx, err := strconv.Atoi(inner); err == nil {
+				segments = append(segments, pathSegment{index: idx, isIndex: true})
+			} else if key, err := strconv.Unquote(inner); err == nil {
+				segments = append(segments, pathSegment{key: key})
+			} else {
+				return nil, fmt.Errorf("index path JSON tidak valid: %q", inner)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path JSON tidak valid: %q", text)
+		}
+	}
+	return segments, nil
+}
+
+// lookup mengikuti path pada dokumen JSON. Segmen "length" pada array,
+// string, atau object yang tidak punya key "length" menghasilkan panjangnya.
+func (e *jsonExpr) lookup(doc any) (any, bool) {
+	current := doc
+	for _, seg := range e.path {
+		switch value := current.(type) {
+		case []any:
+			if seg.isIndex {
+				if seg.index < 0 || seg.index >= len(value) {
+					return nil, false
+				}
+				current = value[seg.index]
+				continue
+			}
+			if seg.key == "length" {
+				current = float64(len(value))
+				continue
+			}
+			return nil, false
+		case map[string]any:
+			if seg.isIndex {
+				return nil, false
+			}
+			field, ok := value[seg.key]
+			if !ok {
+				if seg.key == "length" {
+					current = float64(len(value))
+					continue
+				}
+				return nil, false
+			}
+			current = field
+		case string:
+			if seg.key == "length" && !seg.isIndex {
+				current = float64(len(value))
+				continue
+			}
+			return nil, false
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// eval menjalankan assertion terhadap dokumen JSON. Mengembalikan lolos/gagal
+// beserta penjelasan nilai aktual.
+func (e *jsonExpr) eval(doc any) (bool, string) {
+	actual, found := e.lookup(doc)
+	if !found {
+		return false, "path tidak ditemukan"
+	}
+	actualText := formatJSONValue(actual)
+
+	if e.op == "" {
+		if actual == nil {
+			return false, "nilai null"
+		}
+		return true, "nilai: " + actualText
+	}
+
+	switch e.op {
+	case "==":
+		return reflect.DeepEqual(actual, e.literal), "nilai: " + actualText
+	case "!=":
+		return !reflect.DeepEqual(actual, e.literal), "nilai: " + actualText
+	}
+
+	a, ok := actual.(float64)
+	if !ok {
+		return false, "nilai bukan angka: " + actualText
+	}
+	b := e.literal.(float64)
+	var passed bool
+	switch e.op {
+	case ">":
+		passed = a > b
+	case ">=":
+		passed = a >= b
+	case "<":
+		passed = a < b
+	case "<=":
+		passed = a <= b
+	}
+	return passed, "nilai: " + actualText
+}
+
+// formatJSONValue menampilkan nilai sebagai JSON tanpa escape HTML (< > &)
+func formatJSONValue(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	text := strings.TrimSuffix(buf.String(), "\n")
+	if len(text) > 80 {
+		text = text[:77] + "..."
+	}
+	return text
+}
@@ -0,0 +1,107 @@
+package probe
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testJSONDoc = `{
+	"status": "ok",
+	"db": "up",
+	"count": 5,
+	"version": "2",
+	"nothing": null,
+	"msg": "a >= b",
+	"a.b": "dotted",
+	"a<b": 1,
+	"with space": true,
+	"checks": [{"name": "db", "latency": 120}, {"name": "cache", "latency": 300}],
+	"items": [1, 2, 3],
+	"meta": {"length": 42, "region": "id"},
+	"tags": {"x": 1, "y": 2}
+}`
+
+func TestJSONExprEval(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(testJSONDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr     string
+		wantPass bool
+		wantMsg  string
+	}{
+		// Keberadaan field
+		{`$.status`, true, `nilai: "ok"`},
+		{`$.nothing`, false, "nilai null"},
+		{`$.missing`, false, "path tidak ditemukan"},
+		{`$.meta.missing.deeper`, false, "path tidak ditemukan"},
+		{`$.status.inner`, false, "path tidak ditemukan"},
+		{`$.count[0]`, false, "path tidak ditemukan"},
+
+		// Key dengan tanda kutip
+		{`$["a.b"] == "dotted"`, true, `nilai: "dotted"`},
+		{`$["with space"] == true`, true, "nilai: true"},
+		{`$.meta["region"] == "id"`, true, `nilai: "id"`},
+		{`$["meta"][0]`, false, "path tidak ditemukan"},
+
+		// Index array
+		{`$.checks[0].name == "db"`, true, `nilai: "db"`},
+		{`$.checks[1].latency > 200`, true, "nilai: 300"},
+		{`$.checks[2].latency`, false, "path tidak ditemukan"},
+		{`$.items[-1]`, false, "path tidak ditemukan"},
+		{`$.items.x`, false, "path tidak ditemukan"},
+
+		// length untuk array, string dan object
+		{`$.items.length == 3`, true, "nilai: 3"},
+		{`$.items.length >= 4`, false, "nilai: 3"},
+		{`$.status.length == 2`, true, "nilai: 2"},
+		{`$.tags.length == 2`, true, "nilai: 2"},
+		{`$.meta.length == 42`, true, "nilai: 42"},
+
+		// Operator di dalam literal string atau key
+		{`$.msg == "a >= b"`, true, `nilai: "a >= b"`},
+		{`$.msg != "a < b"`, true, `nilai: "a >= b"`},
+		{`$["a<b"] == 1`, true, "nilai: 1"},
+
+		// Angka dibandingkan dengan string
+		{`$.count == "5"`, false, "nilai: 5"},
+		{`$.count != "5"`, true, "nilai: 5"},
+		{`$.version == 2`, false, `nilai: "2"`},
+		{`$.version > 1`, false, `nilai bukan angka: "2"`},
+		{`$.count <= 5`, true, "nilai: 5"},
+		{`$.count < 5`, false, "nilai: 5"},
+	}
+	for _, tt := range tests {
+		expr, err := parseJSONExpr(tt.expr)
+		if err != nil {
+			t.Errorf("parseJSONExpr(%q): %v", tt.expr, err)
+			continue
+		}
+		passed, msg := expr.eval(doc)
+		if passed != tt.wantPass || msg != tt.wantMsg {
+			t.Errorf("%s = (%v, %q), want (%v, %q)", tt.expr, passed, msg, tt.wantPass, tt.wantMsg)
+		}
+	}
+}
+
+func TestParseJSONExprInvalid(t *testing.T) {
+	tests := []string{
+		``,
+		`status == "ok"`,
+		`$.`,
+		`$..a`,
+		`$.items[0`,
+		`$.items[x]`,
+		`$x`,
+		`$.db == up`,
+		`$.count > "5"`,
+		`$.count >= true`,
+	}
+	for _, text := range tests {
+		if _, err := parseJSONExpr(text); err == nil {
+			t.Errorf("parseJSONExpr(%q) succeeded, want error", text)
+		}
+	}
+}
@@ -1,41 +1,198 @@
 package probe
 
 import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"test/models"
 	"time"
 )
 
+// maxBodyBytes membatasi ukuran body yang dibaca (untuk assertion dan
+// pengukuran waktu transfer)
+const maxBodyBytes = 1 << 20
+
 type ProbeResult struct {
 	StatusCode  int
 	LatencyMs   int64
 	NetworkErr  bool
+
+	// Up adalah verdict akhir probe: status diterima (lihat
+	// TargetURL.StatusAccepted) dan semua assertion lolos
+	Up bool
+
+	// FailedAssertion berisi assertion body yang gagal ("" jika semua lolos)
+	FailedAssertion string
+	// Assertions berisi hasil lolos/gagal setiap assertion
+	Assertions []models.AssertionResult
+	// Cert berisi sertifikat leaf dari TLS handshake (nil untuk HTTP biasa)
+	Cert *models.CertInfo
+	// Timings berisi rincian latency per fase (DNS, connect, TLS, TTFB, transfer)
+	Timings models.PhaseTimings
+	// Attempts adalah jumlah percobaan dalam satu run (diisi oleh scheduler
+	// saat target punya retry)
+	Attempts int
+
+	// Err adalah error jaringan mentah saat NetworkErr
+	Err error
+	// ErrorClass dan ErrorMessage menjelaskan kenapa probe gagal ("" jika up),
+	// diisi oleh Run
+	ErrorClass   models.ErrorClass
+	ErrorMessage string
+}
+
+func init() {
+	Register(models.ProbeHTTP, func() Prober { return &httpProber{} })
+}
+
+// maxRedirects sama dengan batas bawaan http.Client
+const maxRedirects = 10
+
+// errTooManyRedirects dikembalikan CheckRedirect agar redirect loop bisa
+// dikenali oleh classifyNetworkError
+var errTooManyRedirects = errors.New("terlalu banyak redirect")
+
+// validMethods adalah HTTP method yang boleh dipakai oleh target
+var validMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// httpProber menjalankan HTTP probe sesuai konfigurasi target (method,
+// header dan body), mengukur waktu, lalu mengevaluasi status dan assertion.
+type httpProber struct {
+	target models.TargetURL
 }
 
-// DoProbe menjalankan satu kali HTTP GET probe dan mengukur waktu.
-func DoProbe(url string) ProbeResult {
+func (p *httpProber) Configure(target models.TargetURL) error {
+	if !strings.HasPrefix(target.URL, "http://") && !strings.HasPrefix(target.URL, "https://") {
+		return fmt.Errorf("URL HTTP harus diawali http:// atau https://: %q", target.URL)
+	}
+	if target.Method == "" {
+		target.Method = http.MethodGet
+	}
+	if !validMethods[target.Method] {
+		return fmt.Errorf("method tidak valid: %q", target.Method)
+	}
+	if err := validateAssertions(target.Assertions); err != nil {
+		return err
+	}
+	p.target = target
+	return nil
+}
+
+func (p *httpProber) Probe(ctx context.Context) ProbeResult {
+	target := p.target
+
+	req, err := http.NewRequestWithContext(ctx, target.Method, target.URL, strings.NewReader(target.Body))
+	if err != nil {
+		return ProbeResult{
+			StatusCode:  0,
+			LatencyMs:   0,
+			NetworkErr:  true,
+			Err:         err,
+		}
+	}
+	for key, value := range target.Headers {
+		// Header Host harus diset lewat req.Host, bukan req.Header
+		if strings.EqualFold(key, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+
+	tracer := &phaseTracer{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
+
+	// Koneksi baru untuk setiap probe agar fase DNS/connect/TLS selalu terukur
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+
 	startTime := time.Now()
+	tracer.start = startTime
 
+	// Batas waktu diatur lewat ctx (lihat Run)
 	client := http.Client{
-		Timeout: 5 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("berhenti setelah %d redirect: %w", len(via), errTooManyRedirects)
+			}
+			return nil
+		},
 	}
 
-	resp, err := client.Get(url)
-	
+	resp, err := client.Do(req)
+
 	duration := time.Since(startTime)
 	milliseconds := duration.Milliseconds()
 
 	if err != nil {
 		return ProbeResult{
-			StatusCode:  0, 
+			StatusCode:  0,
 			LatencyMs:   milliseconds,
 			NetworkErr:  true,
+			Timings:     tracer.timings(),
+			Err:         err,
 		}
 	}
 	defer resp.Body.Close()
 
-	return ProbeResult{
+	result := ProbeResult{
 		StatusCode:  resp.StatusCode,
 		LatencyMs:   milliseconds,
 		NetworkErr:  false,
 	}
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		result.Cert = leafCertInfo(resp.TLS.PeerCertificates)
+	}
+
+	// Baca satu byte lebih dari batas untuk mengetahui apakah body terpotong
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes+1))
+	truncated := len(body) > maxBodyBytes
+	if truncated {
+		body = body[:maxBodyBytes]
+	}
+	tracer.mark(&tracer.bodyDone)
+	result.Timings = tracer.timings()
+	if err != nil {
+		if len(target.Assertions) > 0 {
+			result.FailedAssertion = fmt.Sprintf("gagal membaca body: %v", err)
+		}
+	} else if len(target.Assertions) > 0 {
+		result.Assertions, result.FailedAssertion = checkAssertions(target.Assertions, body, truncated)
+	}
+
+	result.Up = target.StatusAccepted(result.StatusCode) && result.FailedAssertion == ""
+	return result
+}
+
+// leafCertInfo merangkum sertifikat leaf dari rantai sertifikat peer
+func leafCertInfo(chain []*x509.Certificate) *models.CertInfo {
+	leaf := chain[0]
+	issuer := leaf.Issuer.CommonName
+	if issuer == "" && len(leaf.Issuer.Organization) > 0 {
+		issuer = leaf.Issuer.Organization[0]
+	}
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return &models.CertInfo{
+		Expiry: leaf.NotAfter,
+		Issuer: issuer,
+		SANs:   sans,
+	}
 }
@@ -0,0 +1,104 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"test/models"
+	"time"
+)
+
+// DefaultTimeout adalah batas waktu satu probe jika context tidak punya deadline
+const DefaultTimeout = 5 * time.Second
+
+// Prober adalah satu jenis pemeriksaan (HTTP, TCP, DNS, ...). Instance baru
+// dibuat untuk setiap target lewat Factory yang didaftarkan dengan Register.
+type Prober interface {
+	// Configure membaca pengaturan target dan mengembalikan error jika
+	// pengaturan tidak valid untuk jenis probe ini.
+	Configure(target models.TargetURL) error
+	// Probe menjalankan satu kali pemeriksaan. Implementasi harus berhenti
+	// saat ctx dibatalkan atau melewati deadline.
+	Probe(ctx context.Context) ProbeResult
+}
+
+// Factory membuat Prober baru yang belum dikonfigurasi
+type Factory func() Prober
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register mendaftarkan jenis probe baru. Dipanggil dari init() paket yang
+// menyediakan prober; panic jika nama sudah terdaftar.
+func Register(probeType string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("probe: Register factory is nil for " + probeType)
+	}
+	if _, dup := registry[probeType]; dup {
+		panic("probe: Register called twice for " + probeType)
+	}
+	registry[probeType] = factory
+}
+
+// Types mengembalikan semua jenis probe yang terdaftar (urut alfabet)
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New membuat dan mengonfigurasi Prober sesuai ProbeType target
+// (kosong dianggap HTTP).
+func New(target models.TargetURL) (Prober, error) {
+	probeType := target.ProbeType
+	if probeType == "" {
+		probeType = models.ProbeHTTP
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[probeType]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("jenis probe tidak dikenal: %q", probeType)
+	}
+
+	p := factory()
+	if err := p.Configure(target); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Run membuat prober untuk target dan menjalankannya satu kali. Jika ctx
+// tidak punya deadline, DefaultTimeout dipakai.
+func Run(ctx context.Context, target models.TargetURL) (ProbeResult, error) {
+	p, err := New(target)
+	if err != nil {
+		return ProbeResult{
+			NetworkErr:   true,
+			Err:          err,
+			ErrorClass:   models.ErrorClassConfig,
+			ErrorMessage: err.Error(),
+		}, err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	result := p.Probe(ctx)
+	classify(target, &result)
+	return result, nil
+}
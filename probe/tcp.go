@@ -0,0 +1,120 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"test/models"
+	"time"
+)
+
+// maxBannerBytes membatasi jumlah data yang dibaca saat mencocokkan banner
+const maxBannerBytes = 4096
+
+func init() {
+	Register(models.ProbeTCP, func() Prober { return &tcpProber{} })
+}
+
+// tcpProber membuka koneksi TCP ke target (format host:port) dan mengukur
+// waktu connect. Jika TCPSend diisi, data dikirim setelah terhubung; jika
+// TCPExpect diisi, response (banner) harus mengandung teks tersebut.
+type tcpProber struct {
+	address string
+	send    []byte
+	expect  []byte
+}
+
+func (p *tcpProber) Configure(target models.TargetURL) error {
+	if _, _, err := net.SplitHostPort(target.URL); err != nil {
+		return fmt.Errorf("target TCP harus berformat host:port: %q", target.URL)
+	}
+	p.address = target.URL
+	p.send = []byte(unescapeTCPText(target.TCPSend))
+	p.expect = []byte(unescapeTCPText(target.TCPExpect))
+	return nil
+}
+
+func (p *tcpProber) Probe(ctx context.Context) ProbeResult {
+	startTime := time.Now()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	connectMs := time.Since(startTime).Milliseconds()
+	if err != nil {
+		return ProbeResult{
+			StatusCode: 0,
+			LatencyMs:  connectMs,
+			NetworkErr: true,
+			Err:        err,
+			Timings:    models.PhaseTimings{ConnectMs: connectMs},
+		}
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	result := ProbeResult{
+		LatencyMs: connectMs,
+		Up:        true,
+		Timings:   models.PhaseTimings{ConnectMs: connectMs},
+	}
+
+	if len(p.send) > 0 {
+		if _, err := conn.Write(p.send); err != nil {
+			result.Up = false
+			result.FailedAssertion = fmt.Sprintf("gagal mengirim data: %v", err)
+			return result
+		}
+	}
+
+	if len(p.expect) > 0 {
+		banner, err := readUntil(conn, p.expect)
+		result.Timings.TransferMs = time.Since(startTime).Milliseconds() - connectMs
+		if !bytes.Contains(banner, p.expect) {
+			result.Up = false
+			result.FailedAssertion = fmt.Sprintf("banner tidak mengandung %q (diterima: %q)", p.expect, truncate(banner, 80))
+			if err != nil && len(banner) == 0 {
+				result.FailedAssertion = fmt.Sprintf("gagal membaca banner: %v", err)
+			}
+		}
+	}
+	return result
+}
+
+// readUntil membaca dari koneksi sampai data mengandung expect, koneksi
+// ditutup, deadline tercapai, atau batas maxBannerBytes.
+func readUntil(conn net.Conn, expect []byte) ([]byte, error) {
+	var received []byte
+	buf := make([]byte, 512)
+	for len(received) < maxBannerBytes {
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+		if bytes.Contains(received, expect) {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+	}
+	return received, nil
+}
+
+// unescapeTCPText mengubah escape seperti \r\n menjadi karakter aslinya.
+// Jika teks bukan escape yang valid, teks dipakai apa adanya.
+func unescapeTCPText(text string) string {
+	unquoted, err := strconv.Unquote(`"` + text + `"`)
+	if err != nil {
+		return text
+	}
+	return unquoted
+}
+
+func truncate(b []byte, max int) string {
+	if len(b) > max {
+		return string(b[:max]) + "..."
+	}
+	return string(b)
+}
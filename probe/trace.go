@@ -0,0 +1,95 @@
+package probe
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"test/models"
+	"time"
+)
+
+// phaseTracer mencatat waktu setiap fase request lewat httptrace. Callback
+// bisa dipanggil dari beberapa goroutine sekaligus (dual-stack mencoba
+// koneksi IPv4 dan IPv6 paralel), jadi semua field dijaga mutex.
+type phaseTracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	bodyDone     time.Time
+}
+
+// mark mengisi field dengan waktu sekarang di bawah lock
+func (t *phaseTracer) mark(field *time.Time) {
+	t.mu.Lock()
+	*field = time.Now()
+	t.mu.Unlock()
+}
+
+// reset mengosongkan fase request sebelumnya. Dipanggil di awal setiap
+// request sehingga setelah redirect semua fase menggambarkan hop terakhir.
+func (t *phaseTracer) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+	t.connectStart, t.connectDone = time.Time{}, time.Time{}
+	t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+	t.wroteRequest, t.firstByte, t.bodyDone = time.Time{}, time.Time{}, time.Time{}
+}
+
+func (t *phaseTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn:  func(string) { t.reset() },
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.mark(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			// Hanya catat percobaan koneksi pertama (dual-stack bisa mencoba beberapa alamat)
+			t.mu.Lock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.mark(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart:    func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.mark(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.mark(&t.wroteRequest) },
+		GotFirstResponseByte: func() { t.mark(&t.firstByte) },
+	}
+}
+
+// phaseMs menghitung selisih dalam milidetik, 0 jika salah satu waktu kosong
+func phaseMs(from, to time.Time) int64 {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		return 0
+	}
+	return to.Sub(from).Milliseconds()
+}
+
+// timings merangkum fase yang tercatat. TTFB dihitung dari request selesai
+// dikirim sampai byte pertama response (waktu proses di server).
+func (t *phaseTracer) timings() models.PhaseTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	waitFrom := t.wroteRequest
+	if waitFrom.IsZero() {
+		waitFrom = t.start
+	}
+	return models.PhaseTimings{
+		DNSMs:      phaseMs(t.dnsStart, t.dnsDone),
+		ConnectMs:  phaseMs(t.connectStart, t.connectDone),
+		TLSMs:      phaseMs(t.tlsStart, t.tlsDone),
+		TTFBMs:     phaseMs(waitFrom, t.firstByte),
+		TransferMs: phaseMs(t.firstByte, t.bodyDone),
+	}
+}
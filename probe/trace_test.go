@@ -0,0 +1,66 @@
+package probe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimingsDescribeFinalHop(t *testing.T) {
+	final := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	// Catat kapan hop pertama dilayani: semua fase hop terakhir harus sesudahnya
+	var mu sync.Mutex
+	var firstHop time.Time
+	first := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		firstHop = time.Now()
+		mu.Unlock()
+		http.Redirect(w, r, final.URL, http.StatusFound)
+	})
+
+	tracer := &phaseTracer{start: time.Now()}
+	ctx := httptrace.WithClientTrace(context.Background(), tracer.clientTrace())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, first.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{}).Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	tracer.mark(&tracer.bodyDone)
+
+	mu.Lock()
+	prev := firstHop
+	mu.Unlock()
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	phases := []struct {
+		name string
+		at   time.Time
+	}{
+		{"connectStart", tracer.connectStart},
+		{"connectDone", tracer.connectDone},
+		{"wroteRequest", tracer.wroteRequest},
+		{"firstByte", tracer.firstByte},
+		{"bodyDone", tracer.bodyDone},
+	}
+	for _, phase := range phases {
+		if phase.at.IsZero() {
+			t.Errorf("phase %s not recorded", phase.name)
+			continue
+		}
+		if phase.at.Before(prev) {
+			t.Errorf("phase %s is before the previous phase or the first hop", phase.name)
+		}
+		prev = phase.at
+	}
+}
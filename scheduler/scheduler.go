@@ -1,72 +1,624 @@
 package scheduler
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"test/database"
+	"test/models"
+	"test/notify"
 	"test/probe"
 	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
-// CreateJob adalah fungsi yang mengembalikan fungsi job
-func CreateJob(store *database.Store) func() {
+// ErrBusy dikembalikan ProbeNow jika target sedang diprobe oleh job lain
+var ErrBusy = errors.New("target sedang diprobe")
+
+// Default ukuran worker pool jika setting tidak valid
+const (
+	DefaultConcurrency = 10
+	DefaultPerHost     = 2
+)
+
+// Retensi history (hari) dan jadwal job maintenance (rollup + cleanup)
+const (
+	DefaultRetentionDays = 30
+	MaxRetentionDays     = 3650
+	maintenanceInterval  = "@hourly"
+)
+
+// Scheduler menjadwalkan probe: satu entry cron untuk semua target yang
+// ikut interval global, dan satu entry per target yang punya interval
+// sendiri (TargetURL.CheckInterval). Mengubah jadwal satu target hanya
+// mengganti entry milik target tersebut.
+type Scheduler struct {
+	store    *database.Store
+	notifier *notify.Notifier
+	cron     *cron.Cron
+
+	mu       sync.Mutex
+	globalID cron.EntryID
+	targets  map[int]cron.EntryID
+
+	// globalRunning mencegah job global berjalan ganda jika run sebelumnya
+	// belum selesai (juga setelah job global dijadwalkan ulang)
+	globalRunning atomic.Bool
+	// busy berisi ID target yang sedang diprobe, agar satu target tidak
+	// diprobe dua kali bersamaan oleh job yang berbeda
+	busyMu sync.Mutex
+	busy   map[int]bool
+}
+
+// ValidateInterval memeriksa interval ("@every 30s", "@hourly") atau
+// ekspresi cron 5 field
+func ValidateInterval(spec string) error {
+	if _, err := cron.ParseStandard(spec); err != nil {
+		return fmt.Errorf("interval tidak valid %q: %w", spec, err)
+	}
+	return nil
+}
+
+// Start membuat scheduler, mendaftarkan job global dan job per target,
+// lalu menjalankan cron. Perubahan status target dikirim ke notifier (boleh
+// nil).
+func Start(interval string, store *database.Store, notifier *notify.Notifier) (*Scheduler, error) {
+	log.Printf("Starting scheduler (every %s)...", interval)
+	s := &Scheduler{
+		store:    store,
+		notifier: notifier,
+		cron:     cron.New(),
+		targets:  map[int]cron.EntryID{},
+		busy:     map[int]bool{},
+	}
+
+	id, err := s.cron.AddFunc(interval, s.runGlobal)
+	if err != nil {
+		return nil, fmt.Errorf("interval global tidak valid %q: %w", interval, err)
+	}
+	s.globalID = id
+
+	// Rollup dan cleanup history dijalankan terpisah dari probe agar insert
+	// tetap ringan
+	if _, err := s.cron.AddFunc(maintenanceInterval, s.runMaintenance); err != nil {
+		return nil, err
+	}
+	go s.runMaintenance()
+
+	urls, err := store.GetAllURLs()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range urls {
+		if err := s.Reschedule(u); err != nil {
+			// Target dengan interval rusak tetap bisa diperbaiki lewat UI
+			log.Printf("[CRON] Cannot schedule %s: %v\n", u.URL, err)
+		}
+	}
+
+	s.cron.Start()
+	return s, nil
+}
+
+// SetGlobalInterval mengganti jadwal job global. Target dengan interval
+// sendiri tidak terpengaruh.
+func (s *Scheduler) SetGlobalInterval(interval string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := s.cron.AddFunc(interval, s.runGlobal)
+	if err != nil {
+		return err
+	}
+	s.cron.Remove(s.globalID)
+	s.globalID = id
+	return nil
+}
+
+// Reschedule menyesuaikan entry cron milik target dengan CheckInterval-nya.
+// Target tanpa interval sendiri dilepas dan kembali ikut job global.
+func (s *Scheduler) Reschedule(u models.TargetURL) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var newID cron.EntryID
+	if u.CheckInterval != "" {
+		id, err := s.cron.AddFunc(u.CheckInterval, s.targetJob(u.ID))
+		if err != nil {
+			return err
+		}
+		newID = id
+	}
+
+	if oldID, ok := s.targets[u.ID]; ok {
+		s.cron.Remove(oldID)
+		delete(s.targets, u.ID)
+	}
+	if newID != 0 {
+		s.targets[u.ID] = newID
+		log.Printf("[CRON] Scheduled %s (%s)\n", u.URL, u.CheckInterval)
+	}
+	return nil
+}
+
+// Remove menghapus entry cron milik target (dipanggil saat target dihapus)
+func (s *Scheduler) Remove(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entryID, ok := s.targets[id]; ok {
+		s.cron.Remove(entryID)
+		delete(s.targets, id)
+	}
+}
+
+// lockTarget menandai target sedang diprobe. Mengembalikan false jika
+// target masih diprobe oleh job lain.
+func (s *Scheduler) lockTarget(id int) bool {
+	s.busyMu.Lock()
+	defer s.busyMu.Unlock()
+	if s.busy[id] {
+		return false
+	}
+	s.busy[id] = true
+	return true
+}
+
+func (s *Scheduler) unlockTarget(id int) {
+	s.busyMu.Lock()
+	defer s.busyMu.Unlock()
+	delete(s.busy, id)
+}
+
+// recordRun menyimpan hasil run ke tabel scheduler_runs
+func (s *Scheduler) recordRun(run models.SchedulerRun) {
+	if err := s.store.AddSchedulerRun(run); err != nil {
+		log.Printf("[CRON] Failed to record run: %v\n", err)
+	}
+}
+
+// targetJob mengembalikan job untuk satu target dengan interval sendiri.
+// Target dibaca ulang dari database agar konfigurasi terbaru yang dipakai.
+func (s *Scheduler) targetJob(id int) func() {
 	return func() {
-		log.Println("[CRON] Starting probe...")
-		urls, err := store.GetAllURLs()
+		u, err := s.store.GetURL(id)
 		if err != nil {
-			log.Printf("[CRON] Failed to retrieve URLs: %v\n", err)
+			log.Printf("[CRON] Failed to retrieve URL %d: %v\n", id, err)
+			return
+		}
+		if u.CheckInterval == "" {
 			return
 		}
 
-		if len(urls) == 0 {
-			log.Println("[CRON] No URLs to probe.")
+		run := models.SchedulerRun{Scope: models.RunScopeTarget, URLID: id, StartedAt: time.Now()}
+		if !s.lockTarget(id) {
+			log.Printf("[CRON] Probe %s still running, skipping.\n", u.URL)
+			run.FinishedAt = run.StartedAt
+			run.Skipped = true
+			s.recordRun(run)
 			return
 		}
+		defer s.unlockTarget(id)
 
-		// Jalankan probe untuk setiap URL
-		for _, u := range urls {
-			result := probe.DoProbe(u.URL)
+		run.Targets = 1
+		if !s.probeTarget(u, time.Sleep) {
+			run.Failures = 1
+		}
+		run.FinishedAt = time.Now()
+		s.recordRun(run)
+	}
+}
 
-			// --- LOGIKA UPTIME ---
-			var newFirstUpTime sql.NullTime = u.FirstUpTime
-			wasUp := (u.LastStatus == 200)
-			isNowUp := (result.StatusCode == 200)
+// runGlobal adalah job untuk semua target yang ikut interval global. Run
+// dilewati (dan dicatat sebagai skipped) jika run sebelumnya belum selesai.
+func (s *Scheduler) runGlobal() {
+	run := models.SchedulerRun{Scope: models.RunScopeGlobal, StartedAt: time.Now()}
+	if !s.globalRunning.CompareAndSwap(false, true) {
+		log.Println("[CRON] Previous run still in progress, skipping.")
+		run.FinishedAt = run.StartedAt
+		run.Skipped = true
+		s.recordRun(run)
+		return
+	}
+	defer s.globalRunning.Store(false)
 
-			if !wasUp && isNowUp {
-				newFirstUpTime = sql.NullTime{Time: time.Now(), Valid: true}
-			} else if wasUp && !isNowUp {
-				newFirstUpTime = sql.NullTime{Time: time.Time{}, Valid: false}
-			}
+	log.Println("[CRON] Starting probe...")
+	allURLs, err := s.store.GetAllURLs()
+	if err != nil {
+		log.Printf("[CRON] Failed to retrieve URLs: %v\n", err)
+		run.FinishedAt = time.Now()
+		run.Error = err.Error()
+		s.recordRun(run)
+		return
+	}
 
-			if result.StatusCode > 0 {
-				err = store.UpdateProbeStats(u.ID, result.StatusCode, result.LatencyMs, newFirstUpTime)
-				if err == nil {
-					err = store.AddProbeHistory(u.ID, result.LatencyMs)
-				}
-			} else {
-				err = store.UpdateProbeNetworkError(u.ID, result.LatencyMs, newFirstUpTime)
-			}
+	// Target dengan interval sendiri dijalankan oleh job miliknya
+	var urls []models.TargetURL
+	for _, u := range allURLs {
+		if u.CheckInterval == "" {
+			urls = append(urls, u)
+		}
+	}
 
-			if err != nil {
-				log.Printf("[CRON] Failed to update DB for %s: %v\n", u.URL, err)
-			} else {
-				log.Printf("[CRON] Probe %s -> Status: %d, Latency: %dms\n", u.URL, result.StatusCode, result.LatencyMs)
-			}
+	if len(urls) == 0 {
+		log.Println("[CRON] No URLs to probe.")
+		run.FinishedAt = time.Now()
+		s.recordRun(run)
+		return
+	}
+
+	s.probeBatch(&run, urls)
+	s.recordRun(run)
+
+	log.Printf("[CRON] Probe finished: %d targets, %d down, took %s.\n",
+		run.Targets, run.Failures, run.GetDuration())
+}
+
+// runMaintenance memperbarui rollup lalu menghapus history, rollup dan run
+// log yang melewati masa retensi. Rollup dijalankan lebih dulu agar history
+// tidak terhapus sebelum dirangkum.
+func (s *Scheduler) runMaintenance() {
+	now := time.Now()
+	if err := s.store.UpdateRollups(now); err != nil {
+		log.Printf("[CRON] Rollup update failed: %v\n", err)
+		return
+	}
+	if _, err := s.store.CleanupRollups(now); err != nil {
+		log.Printf("[CRON] Rollup cleanup failed: %v\n", err)
+	}
+
+	days := s.store.GetIntSetting("history_retention_days", DefaultRetentionDays)
+	if days <= 0 {
+		days = DefaultRetentionDays
+	}
+	deleted, err := s.store.CleanupProbeHistory(days)
+	if err != nil {
+		log.Printf("[CRON] History cleanup failed: %v\n", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("[CRON] History cleanup removed %d rows.\n", deleted)
+	}
+}
+
+// probeBatch memprobe urls lewat worker pool dan mengisi Targets, Failures
+// dan FinishedAt pada run. Target yang sedang diprobe job lain dilewati.
+func (s *Scheduler) probeBatch(run *models.SchedulerRun, urls []models.TargetURL) {
+	concurrency := s.store.GetIntSetting("probe_concurrency", DefaultConcurrency)
+	perHost := s.store.GetIntSetting("probe_per_host", DefaultPerHost)
+
+	// Jalankan probe untuk setiap URL lewat worker pool
+	var countMu sync.Mutex
+	runPool(urls, concurrency, perHost, time.Sleep, func(u models.TargetURL, sleep func(time.Duration)) {
+		if !s.lockTarget(u.ID) {
+			log.Printf("[CRON] Probe %s still running, skipping.\n", u.URL)
+			return
 		}
-		log.Println("[CRON] Probe finished.")
+		defer s.unlockTarget(u.ID)
+
+		up := s.probeTarget(u, sleep)
+		countMu.Lock()
+		run.Targets++
+		if !up {
+			run.Failures++
+		}
+		countMu.Unlock()
+	})
+	run.FinishedAt = time.Now()
+}
+
+// ProbeNow langsung memprobe satu target di luar jadwal dan mengembalikan
+// kondisi target setelah hasilnya disimpan. Mengembalikan ErrBusy jika
+// target sedang diprobe oleh job lain.
+func (s *Scheduler) ProbeNow(id int) (models.TargetURL, error) {
+	u, err := s.store.GetURL(id)
+	if err != nil {
+		return u, err
+	}
+	if !s.lockTarget(id) {
+		return u, ErrBusy
+	}
+	defer s.unlockTarget(id)
+
+	run := models.SchedulerRun{Scope: models.RunScopeManual, URLID: id, StartedAt: time.Now(), Targets: 1}
+	if !s.probeTarget(u, time.Sleep) {
+		run.Failures = 1
 	}
+	run.FinishedAt = time.Now()
+	s.recordRun(run)
+
+	return s.store.GetURL(id)
 }
 
-// StartScheduler starts the cron job
-func StartScheduler(interval string, store *database.Store) (*cron.Cron, cron.EntryID) {
-	log.Printf("Starting scheduler (every %s)...", interval)
-	c := cron.New()
+// ProbeAllNow langsung memprobe semua target (termasuk yang punya interval
+// sendiri) dan mengembalikan ringkasan run beserta kondisi terbaru target.
+func (s *Scheduler) ProbeAllNow() (models.SchedulerRun, []models.TargetURL, error) {
+	run := models.SchedulerRun{Scope: models.RunScopeManual, StartedAt: time.Now()}
+	urls, err := s.store.GetAllURLs()
+	if err != nil {
+		return run, nil, err
+	}
+
+	log.Println("[CRON] Starting manual probe...")
+	s.probeBatch(&run, urls)
+	s.recordRun(run)
+	log.Printf("[CRON] Manual probe finished: %d targets, %d down, took %s.\n",
+		run.Targets, run.Failures, run.GetDuration())
+
+	urls, err = s.store.GetAllURLs()
+	return run, urls, err
+}
+
+// probeTarget menjalankan probe untuk satu target dan menyimpan hasilnya
+// (uptime, statistik, history). Mengembalikan true jika target up setelah
+// dikonfirmasi (lihat TargetURL.ConfirmState).
+func (s *Scheduler) probeTarget(u models.TargetURL, sleep func(time.Duration)) bool {
+	store := s.store
+	result, err := probeWithRetry(u, sleep)
+	if err != nil {
+		log.Printf("[CRON] Cannot probe %s: %v\n", u.URL, err)
+	}
+
+	// Target yang belum pernah diprobe dianggap up, sehingga kegagalan
+	// pertama yang terkonfirmasi tetap membuka incident dan mengirim alert
+	if u.IsUnchecked() {
+		u.IsUp = true
+	}
+
+	// --- KONFIRMASI KEGAGALAN ---
+	consecutiveFailures := 0
+	if !result.Up {
+		consecutiveFailures = u.ConsecutiveFailures + 1
+	}
+	isNowUp := u.ConfirmState(result.Up, consecutiveFailures)
+	if !result.Up && isNowUp {
+		log.Printf("[CRON] Probe %s failed (%d/%d), waiting for confirmation\n",
+			u.URL, consecutiveFailures, u.GetFailureThreshold())
+	}
+
+	// --- LOGIKA UPTIME ---
+	var newFirstUpTime sql.NullTime = u.FirstUpTime
+	wasUp := u.IsUp
 
-	// Use the 'interval' from the arguments
-	id, _ := c.AddFunc(interval, CreateJob(store))
-	c.Start()
+	if isNowUp && !newFirstUpTime.Valid {
+		newFirstUpTime = sql.NullTime{Time: time.Now(), Valid: true}
+	} else if !isNowUp {
+		newFirstUpTime = sql.NullTime{Time: time.Time{}, Valid: false}
+	}
+	// --- AMBANG LATENCY ---
+	latencyLevel, latencyCheckMs := s.latencyLevel(u, result, isNowUp)
+	if isNowUp && latencyLevel != u.LatencyLevel {
+		if latencyLevel == models.LatencyOK {
+			log.Printf("[CRON] Latency of %s back below threshold (%dms)\n", u.URL, latencyCheckMs)
+		} else {
+			log.Printf("[CRON] Latency of %s reached %s threshold (%dms)\n", u.URL, latencyLevel, latencyCheckMs)
+		}
+	}
+
+	state := models.CheckState{
+		IsUp:                isNowUp,
+		FirstUpTime:         newFirstUpTime,
+		ConsecutiveFailures: consecutiveFailures,
+		LatencyLevel:        latencyLevel,
+		LatencyCheckMs:      latencyCheckMs,
+	}
+
+	outcome := probeOutcome(result)
+	if !result.NetworkErr {
+		err = store.UpdateProbeStats(u.ID, outcome, state)
+		if err == nil && result.Cert != nil {
+			err = store.UpdateCertInfo(u.ID, *result.Cert)
+		}
+	} else {
+		err = store.UpdateProbeNetworkError(u.ID, outcome, state)
+	}
+	// Semua probe dicatat di history, termasuk yang gagal
+	if err == nil {
+		err = store.AddProbeHistory(u.ID, outcome)
+	}
+
+	// Incident dibuka saat up -> down dan ditutup saat pulih; keduanya
+	// dikirim sebagai alert, begitu juga perubahan tingkat latency selama
+	// target tetap up (degraded / pulih dari degraded)
+	if err == nil {
+		var changed bool
+		changed, err = updateIncident(store, u, outcome, wasUp, isNowUp, consecutiveFailures)
+		if changed || (wasUp && isNowUp && latencyLevel != u.LatencyLevel) {
+			s.notifier.Notify(alertEvent(u, result, state))
+		}
+	}
+
+	if err != nil {
+		log.Printf("[CRON] Failed to update DB for %s: %v\n", u.URL, err)
+	} else {
+		log.Printf("[CRON] Probe %s -> Status: %d, Latency: %dms\n", u.URL, result.StatusCode, result.LatencyMs)
+		if result.ErrorMessage != "" {
+			log.Printf("[CRON] Probe %s -> %s\n", u.URL, result.ErrorMessage)
+		}
+	}
+	return isNowUp
+}
+
+// probeOutcome memetakan hasil probe ke bentuk yang disimpan database
+func probeOutcome(result probe.ProbeResult) models.ProbeOutcome {
+	return models.ProbeOutcome{
+		StatusCode:      result.StatusCode,
+		LatencyMs:       result.LatencyMs,
+		Up:              result.Up,
+		FailedAssertion: result.FailedAssertion,
+		Assertions:      result.Assertions,
+		Timings:         result.Timings,
+		Attempts:        result.Attempts,
+		ErrorClass:      result.ErrorClass,
+		ErrorMessage:    result.ErrorMessage,
+	}
+}
 
-	return c, id
+// updateIncident membuka, memperbarui atau menutup incident target sesuai
+// perubahan status setelah dikonfirmasi. changed bernilai true jika incident
+// dibuka atau ditutup.
+func updateIncident(store *database.Store, u models.TargetURL, result models.ProbeOutcome, wasUp, isNowUp bool, consecutiveFailures int) (changed bool, err error) {
+	switch {
+	case wasUp && !isNowUp:
+		log.Printf("[CRON] Incident opened for %s\n", u.URL)
+		return true, store.OpenIncident(u.ID, result, consecutiveFailures)
+	case !wasUp && isNowUp:
+		closed, err := store.CloseIncident(u.ID, time.Now())
+		if closed {
+			log.Printf("[CRON] Incident closed for %s\n", u.URL)
+		}
+		return closed, err
+	case !isNowUp:
+		return false, store.CountIncidentProbe(u.ID)
+	}
+	return false, nil
+}
+
+// latencyLevel menghitung tingkat latency target setelah run ini. Hanya
+// probe yang up yang diukur; run gagal yang belum dikonfirmasi down
+// mempertahankan tingkat sebelumnya.
+func (s *Scheduler) latencyLevel(u models.TargetURL, result probe.ProbeResult, isNowUp bool) (string, int64) {
+	if !isNowUp || !u.HasLatencyThresholds() {
+		return models.LatencyOK, 0
+	}
+	if !result.Up {
+		return u.LatencyLevel, u.LatencyCheckMs
+	}
+
+	checkMs := result.LatencyMs
+	if n := u.GetLatencyAvgProbes(); n > 1 {
+		// Probe ini belum masuk history, jadi cukup ambil n-1 probe sebelumnya
+		recent, err := s.store.GetRecentLatencies(u.ID, n-1)
+		if err != nil {
+			log.Printf("[CRON] Cannot read recent latencies for %s: %v\n", u.URL, err)
+		}
+		sum := result.LatencyMs
+		for _, l := range recent {
+			sum += l
+		}
+		checkMs = sum / int64(len(recent)+1)
+	}
+	return u.LatencyLevelFor(checkMs), checkMs
+}
+
+// alertEvent membuat alert perubahan status target (down, degraded atau
+// up) dari hasil probe terakhir dan status barunya
+func alertEvent(u models.TargetURL, result probe.ProbeResult, state models.CheckState) models.AlertEvent {
+	next := u
+	next.IsUp, next.LatencyLevel = state.IsUp, state.LatencyLevel
+
+	event := models.AlertEvent{
+		URLID:      u.ID,
+		URL:        u.URL,
+		ProbeType:  u.ProbeType,
+		OldState:   u.AlertState(),
+		NewState:   next.AlertState(),
+		StatusCode: result.StatusCode,
+		ErrorClass: result.ErrorClass,
+		Error:      result.ErrorMessage,
+		LatencyMs:  result.LatencyMs,
+		Timestamp:  time.Now(),
+	}
+	if event.NewState == models.StateDegraded {
+		event.Level = state.LatencyLevel
+		event.Error = u.LatencyReason(state.LatencyLevel, state.LatencyCheckMs)
+	}
+	return event
+}
+
+// probeWithRetry menjalankan probe hingga 1+RetryCount kali dengan jeda
+// RetryDelayMs, berhenti pada percobaan pertama yang up. Mengembalikan hasil
+// percobaan terakhir. Jeda dijalankan lewat sleep (lihat runPool).
+func probeWithRetry(u models.TargetURL, sleep func(time.Duration)) (probe.ProbeResult, error) {
+	var result probe.ProbeResult
+	var err error
+	for attempt := 1; ; attempt++ {
+		result, err = probe.Run(context.Background(), u)
+		result.Attempts = attempt
+		if err != nil || result.Up || attempt > u.RetryCount {
+			return result, err
+		}
+		log.Printf("[CRON] Probe %s attempt %d failed, retrying\n", u.URL, attempt)
+		sleep(time.Duration(u.RetryDelayMs) * time.Millisecond)
+	}
+}
+
+// runPool menjalankan fn untuk setiap target dengan paling banyak
+// concurrency probe sekaligus, dan paling banyak perHost probe ke host yang
+// sama. Kembali setelah semua target selesai. fn menerima fungsi sleep yang
+// melepas slot selama menunggu (lewat wait, biasanya time.Sleep), sehingga
+// jeda retry tidak menahan target lain.
+func runPool(urls []models.TargetURL, concurrency, perHost int, wait func(time.Duration), fn func(u models.TargetURL, sleep func(time.Duration))) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if perHost <= 0 {
+		perHost = DefaultPerHost
+	}
+
+	global := make(chan struct{}, concurrency)
+	hosts := map[string]chan struct{}{}
+	for _, u := range urls {
+		key := hostKey(u)
+		if _, ok := hosts[key]; !ok {
+			hosts[key] = make(chan struct{}, perHost)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u models.TargetURL) {
+			defer wg.Done()
+			// Ambil slot host dulu agar slot global tidak tertahan oleh
+			// target yang masih menunggu host yang sama
+			hostSem := hosts[hostKey(u)]
+			acquire := func() {
+				hostSem <- struct{}{}
+				global <- struct{}{}
+			}
+			release := func() {
+				<-global
+				<-hostSem
+			}
+
+			acquire()
+			defer release()
+			fn(u, func(d time.Duration) {
+				release()
+				wait(d)
+				acquire()
+			})
+		}(u)
+	}
+	wg.Wait()
+}
+
+// hostKey menentukan host yang dibebani oleh probe target, dipakai untuk
+// batas per host di runPool.
+func hostKey(u models.TargetURL) string {
+	switch u.ProbeType {
+	case models.ProbeTCP:
+		if host, _, err := net.SplitHostPort(u.URL); err == nil {
+			return host
+		}
+	case models.ProbeDNS:
+		// Probe DNS membebani resolver, bukan domain yang di-resolve
+		if u.DNSResolver != "" {
+			return "dns:" + u.DNSResolver
+		}
+		return "dns:system"
+	default:
+		if parsed, err := url.Parse(u.URL); err == nil && parsed.Hostname() != "" {
+			return parsed.Hostname()
+		}
+	}
+	return u.URL
 }
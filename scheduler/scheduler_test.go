@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"test/database"
+	"test/models"
+	"test/notify"
+	"testing"
+	"time"
+)
+
+func TestRunPoolReleasesSlotsWhileSleeping(t *testing.T) {
+	urls := []models.TargetURL{
+		{ID: 1, URL: "https://example.com/a"},
+		{ID: 2, URL: "https://example.com/b"},
+	}
+
+	// Dengan satu slot, kedua target hanya bisa menunggu bersamaan jika slot
+	// dilepas selama sleep. wait menahan setiap sleep sampai keduanya masuk.
+	waiting := make(chan time.Duration, len(urls))
+	resume := make(chan struct{})
+	wait := func(d time.Duration) {
+		waiting <- d
+		<-resume
+	}
+	done := make(chan struct{})
+	go func() {
+		runPool(urls, 1, 1, wait, func(u models.TargetURL, sleep func(time.Duration)) {
+			sleep(200 * time.Millisecond)
+		})
+		close(done)
+	}()
+
+	for range urls {
+		select {
+		case d := <-waiting:
+			if d != 200*time.Millisecond {
+				t.Errorf("wait(%s), want 200ms", d)
+			}
+		case <-done:
+			t.Fatal("runPool returned before every target slept")
+		case <-time.After(5 * time.Second):
+			t.Fatal("retry delays do not overlap: the slot is held while sleeping")
+		}
+	}
+	close(resume)
+	<-done
+}
+
+// newTestScheduler membuat Scheduler dengan database sementara dan satu
+// channel webhook. Alert yang terkirim dikirim ke channel hasil.
+func newTestScheduler(t *testing.T) (*Scheduler, <-chan map[string]any) {
+	t.Helper()
+	store := database.NewStore(filepath.Join(t.TempDir(), "probe.db"))
+	t.Cleanup(func() { store.Db.Close() })
+
+	alerts := make(chan map[string]any, 4)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		alerts <- payload
+	}))
+	t.Cleanup(hook.Close)
+	if _, err := store.AddChannel(models.NotificationChannel{Name: "hook", Type: models.ChannelWebhook, URL: hook.URL, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scheduler{store: store, notifier: notify.New(store), busy: map[int]bool{}}
+	return s, alerts
+}
+
+func TestFirstProbeDownOpensIncident(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		status       int
+		wantUp       bool
+		wantIncident bool
+	}{
+		{"down sejak probe pertama", http.StatusServiceUnavailable, false, true},
+		{"up sejak probe pertama", http.StatusOK, true, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, alerts := newTestScheduler(t)
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer target.Close()
+
+			id, err := s.store.AddURL(models.TargetURL{URL: target.URL, ProbeType: models.ProbeHTTP, Method: http.MethodGet, ExpectedStatus: "200"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := s.ProbeNow(id)
+			if err != nil {
+				t.Fatalf("ProbeNow: %v", err)
+			}
+			if u.IsUp != tt.wantUp {
+				t.Errorf("IsUp = %v, want %v", u.IsUp, tt.wantUp)
+			}
+			if tt.wantUp && !u.FirstUpTime.Valid {
+				t.Error("FirstUpTime not set for a target that is up")
+			}
+
+			incidents, err := s.store.GetIncidents(id, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(incidents) == 1 && incidents[0].IsOpen(); got != tt.wantIncident {
+				t.Errorf("open incident = %v (%+v), want %v", got, incidents, tt.wantIncident)
+			}
+
+			select {
+			case alert := <-alerts:
+				if !tt.wantIncident {
+					t.Errorf("unexpected alert %v", alert)
+				} else if alert["old_state"] != models.StateUp || alert["new_state"] != models.StateDown {
+					t.Errorf("alert state = %v -> %v, want up -> down", alert["old_state"], alert["new_state"])
+				}
+			case <-time.After(time.Second):
+				if tt.wantIncident {
+					t.Error("no down alert sent")
+				}
+			}
+		})
+	}
+}
@@ -10,6 +10,8 @@ function initChart(historyData) {
         hour: '2-digit', minute: '2-digit'
     }));
     const latencyValues = sorted.map(d => d.LatencyMs);
+    // Probe yang gagal ditandai titik merah
+    const failed = sorted.map(d => d.IsUp === false);
 
     const config = {
         type: 'line',
@@ -22,8 +24,10 @@ function initChart(historyData) {
                 borderColor: '#25c17e',
                 borderWidth: 2,
                 tension: 0.35,
-                pointRadius: 0,
-                pointHoverRadius: 0,
+                pointRadius: failed.map(f => f ? 4 : 0),
+                pointHoverRadius: failed.map(f => f ? 6 : 0),
+                pointBackgroundColor: '#ff3b3b',
+                pointBorderColor: '#ff3b3b',
                 segment: {
                     borderColor: ctx => {
                         const i = ctx.p0DataIndex;
@@ -58,10 +62,15 @@ function initChart(historyData) {
                     displayColors: false,
                     callbacks: {
                         title: function(items){
-                            return 'Latency';
+                            const d = sorted[items[0].dataIndex];
+                            return d.IsUp === false ? 'Down' : 'Latency';
                         },
                         label: function(context) {
                             const value = context.parsed.y;
+                            const d = sorted[context.dataIndex];
+                            if (d.IsUp === false && d.ErrorMessage) {
+                                return [value + ' ms', d.ErrorMessage];
+                            }
                             return value + ' ms';
                         }
                     }
@@ -100,4 +109,196 @@ function initChart(historyData) {
     if (ctx) {
         new Chart(ctx.getContext('2d'), config);
     }
-}
\ No newline at end of file
+}
+// Stacked bar chart rincian latency per fase (DNS, connect, TLS, TTFB, transfer)
+function initPhaseChart(historyData) {
+    if (!historyData || historyData.length === 0) {
+        return;
+    }
+
+    const sorted = [...historyData].sort((a, b) => new Date(a.Timestamp) - new Date(b.Timestamp));
+    const labels = sorted.map(d => new Date(d.Timestamp).toLocaleString('id-ID', {
+        hour: '2-digit', minute: '2-digit'
+    }));
+    const phases = [
+        { key: 'DNSMs', label: 'DNS', color: '#64b5f6' },
+        { key: 'ConnectMs', label: 'Connect', color: '#ba68c8' },
+        { key: 'TLSMs', label: 'TLS', color: '#ffb74d' },
+        { key: 'TTFBMs', label: 'TTFB', color: '#25c17e' },
+        { key: 'TransferMs', label: 'Transfer', color: '#ef5350' },
+    ];
+
+    const config = {
+        type: 'bar',
+        data: {
+            labels: labels,
+            datasets: phases.map(p => ({
+                label: p.label,
+                data: sorted.map(d => d[p.key] || 0),
+                backgroundColor: p.color,
+                borderWidth: 0,
+                stack: 'phases'
+            }))
+        },
+        options: {
+            responsive: true,
+            maintainAspectRatio: true,
+            plugins: {
+                legend: {
+                    display: true,
+                    labels: { color: 'rgba(255, 255, 255, 0.8)' }
+                },
+                tooltip: {
+                    backgroundColor: 'rgba(18, 20, 23, 0.95)',
+                    mode: 'index',
+                    intersect: false,
+                    callbacks: {
+                        label: function(context) {
+                            return context.dataset.label + ': ' + context.parsed.y + ' ms';
+                        }
+                    }
+                }
+            },
+            scales: {
+                y: {
+                    stacked: true,
+                    beginAtZero: true,
+                    ticks: {
+                        color: 'rgba(255, 255, 255, 0.7)',
+                        callback: function(value) {
+                            return value + ' ms';
+                        }
+                    },
+                    grid: {
+                        color: 'rgba(255, 255, 255, 0.06)'
+                    }
+                },
+                x: {
+                    stacked: true,
+                    ticks: {
+                        color: 'rgba(255, 255, 255, 0.6)',
+                        maxRotation: 0,
+                        minRotation: 0,
+                        autoSkip: true,
+                        maxTicksLimit: 8
+                    },
+                    grid: {
+                        display: false
+                    }
+                }
+            }
+        }
+    };
+
+    const ctx = document.getElementById('phaseChart');
+    if (ctx) {
+        new Chart(ctx.getContext('2d'), config);
+    }
+}
+// Chart rollup (per jam / per hari): garis avg, p95 dan max. Bucket yang
+// berisi probe gagal ditandai titik merah.
+function initRollupChart(rollupData, resolution) {
+    if (!rollupData || rollupData.length === 0) {
+        return;
+    }
+
+    const daily = resolution === 'daily';
+    const labels = rollupData.map(d => new Date(d.Bucket).toLocaleString('id-ID', daily
+        ? { day: '2-digit', month: 'short' }
+        : { day: '2-digit', month: 'short', hour: '2-digit', minute: '2-digit' }));
+    const failed = rollupData.map(d => d.Failures > 0);
+
+    const config = {
+        type: 'line',
+        data: {
+            labels: labels,
+            datasets: [{
+                label: 'Avg',
+                data: rollupData.map(d => d.AvgMs),
+                fill: true,
+                borderColor: '#25c17e',
+                backgroundColor: 'rgba(37, 193, 126, 0.12)',
+                borderWidth: 2,
+                tension: 0.35,
+                pointRadius: failed.map(f => f ? 4 : 0),
+                pointHoverRadius: failed.map(f => f ? 6 : 3),
+                pointBackgroundColor: failed.map(f => f ? '#ff3b3b' : '#25c17e'),
+                pointBorderColor: failed.map(f => f ? '#ff3b3b' : '#25c17e')
+            }, {
+                label: 'p95',
+                data: rollupData.map(d => d.P95Ms),
+                borderColor: '#ffb74d',
+                borderWidth: 1.5,
+                tension: 0.35,
+                pointRadius: 0,
+                fill: false
+            }, {
+                label: 'Max',
+                data: rollupData.map(d => d.MaxMs),
+                borderColor: 'rgba(239, 83, 80, 0.6)',
+                borderDash: [4, 4],
+                borderWidth: 1,
+                tension: 0.35,
+                pointRadius: 0,
+                fill: false
+            }]
+        },
+        options: {
+            responsive: true,
+            maintainAspectRatio: true,
+            interaction: { mode: 'index', intersect: false },
+            plugins: {
+                legend: {
+                    display: true,
+                    labels: { color: 'rgba(255, 255, 255, 0.8)' }
+                },
+                tooltip: {
+                    backgroundColor: 'rgba(18, 20, 23, 0.95)',
+                    callbacks: {
+                        label: function(context) {
+                            return context.dataset.label + ': ' + context.parsed.y + ' ms';
+                        },
+                        footer: function(items) {
+                            const d = rollupData[items[0].dataIndex];
+                            return [
+                                'Min ' + d.MinMs + ' ms · p50 ' + d.P50Ms + ' ms · p99 ' + d.P99Ms + ' ms',
+                                d.Count + ' probe, ' + d.Failures + ' gagal'
+                            ];
+                        }
+                    }
+                }
+            },
+            scales: {
+                y: {
+                    beginAtZero: true,
+                    ticks: {
+                        color: 'rgba(255, 255, 255, 0.7)',
+                        callback: function(value) {
+                            return value + ' ms';
+                        }
+                    },
+                    grid: {
+                        color: 'rgba(255, 255, 255, 0.06)'
+                    }
+                },
+                x: {
+                    ticks: {
+                        color: 'rgba(255, 255, 255, 0.6)',
+                        maxRotation: 0,
+                        minRotation: 0,
+                        autoSkip: true,
+                        maxTicksLimit: 8
+                    },
+                    grid: {
+                        display: false
+                    }
+                }
+            }
+        }
+    };
+
+    const ctx = document.getElementById('latencyChart');
+    if (ctx) {
+        new Chart(ctx.getContext('2d'), config);
+    }
+}